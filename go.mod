@@ -1,10 +1,21 @@
 module github.com/hwangtaeseung/neptune-core
 
-go 1.16
+go 1.21
 
 require (
 	github.com/aws/aws-sdk-go v1.42.17
 	github.com/gorilla/mux v1.8.0
 	github.com/gorilla/websocket v1.4.2
+	golang.org/x/net v0.0.0-20210614182718-04defd469f4e
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.42.0
+	google.golang.org/protobuf v1.25.0
+	gopkg.in/yaml.v2 v2.2.8
+)
+
+require (
+	github.com/golang/protobuf v1.4.3 // indirect
+	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	golang.org/x/sys v0.0.0-20210423082822-04245dca01da // indirect
+	golang.org/x/text v0.3.6 // indirect
 )
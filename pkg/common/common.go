@@ -1,7 +1,12 @@
 package common
 
+import "github.com/hwangtaeseung/neptune-core/pkg/logging"
+
 // DefaultMaxRetryCount 최대 재시도 횟수 기본값
 const DefaultMaxRetryCount = 5
 
 // DefaultProgressUpdateInterval  처리정보 갱신 주기 unit (seconds)
-const DefaultProgressUpdateInterval = 30
\ No newline at end of file
+const DefaultProgressUpdateInterval = 30
+
+// logger common package 로거 (logging.Configure("common", ...) 로 설정)
+var logger = logging.For("common")
@@ -1,7 +1,6 @@
 package common
 
 import (
-	"log"
 	"syscall"
 )
 
@@ -25,7 +24,7 @@ func DiskUsage(path string) (*DiskInfo, error) {
 
 	fileState := syscall.Statfs_t{}
 	if err := syscall.Statfs(path, &fileState); err != nil {
-		log.Printf("disk usage error : %v\n", err)
+		logger.Error("disk usage error", "path", path, "err", err)
 		return nil, err
 	}
 
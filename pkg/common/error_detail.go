@@ -0,0 +1,174 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// maxStackDepth 저장하는 최대 frame 수
+const maxStackDepth = 32
+
+var errorStackEnabled int32
+
+// EnableErrorStack 켜면 New, Copy 에서 호출 위치의 stack 을 저장 (%+v 로 출력). 기본은 꺼져 있음
+func EnableErrorStack(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&errorStackEnabled, value)
+}
+
+func captureStack() []uintptr {
+	if atomic.LoadInt32(&errorStackEnabled) == 0 {
+		return nil
+	}
+	// runtime.Callers, callers, captureStack, New (Copy) 제외
+	return callers(4)
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+	return pcs[:n]
+}
+
+// WithStack EnableErrorStack 설정과 관계없이 호출 위치의 stack 을 저장한 복사본
+func (h *NeptuneError) WithStack() *NeptuneError {
+	copied := h.clone()
+	copied.stack = callers(3)
+	return copied
+}
+
+// StackTrace 저장된 stack ("function file:line"). 저장하지 않았으면 nil
+func (h *NeptuneError) StackTrace() []string {
+	if len(h.stack) == 0 {
+		return nil
+	}
+	var trace []string
+	frames := runtime.CallersFrames(h.stack)
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, fmt.Sprintf("%v %v:%v", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return trace
+}
+
+// WithField key, value 를 추가한 복사본 (ErrExecFailed 같은 공용 에러는 변경되지 않는다)
+func (h *NeptuneError) WithField(key string, value interface{}) *NeptuneError {
+	copied := h.clone()
+	copied.fields = h.copyFields(1)
+	copied.fields[key] = value
+	return copied
+}
+
+func (h *NeptuneError) WithFields(fields map[string]interface{}) *NeptuneError {
+	copied := h.clone()
+	copied.fields = h.copyFields(len(fields))
+	for key, value := range fields {
+		copied.fields[key] = value
+	}
+	return copied
+}
+
+// Fields WithField 로 추가한 정보의 복사본
+func (h *NeptuneError) Fields() map[string]interface{} {
+	return h.copyFields(0)
+}
+
+// Field err chain 에서 key 를 가진 가장 바깥쪽 NeptuneError 의 값
+func Field(err error, key string) (interface{}, bool) {
+	for err != nil {
+		if neptuneError, ok := err.(*NeptuneError); ok {
+			if value, ok := neptuneError.fields[key]; ok {
+				return value, true
+			}
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = unwrapper.Unwrap()
+	}
+	return nil, false
+}
+
+func (h *NeptuneError) clone() *NeptuneError {
+	copied := *h
+	return &copied
+}
+
+func (h *NeptuneError) copyFields(extra int) map[string]interface{} {
+	if len(h.fields) == 0 && extra == 0 {
+		return nil
+	}
+	fields := make(map[string]interface{}, len(h.fields)+extra)
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	return fields
+}
+
+// fieldString key 순서로 정렬한 "key=value ..."
+func (h *NeptuneError) fieldString() string {
+	keys := make([]string, 0, len(h.fields))
+	for key := range h.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for index, key := range keys {
+		if index > 0 {
+			builder.WriteByte(' ')
+		}
+		_, _ = fmt.Fprintf(&builder, "%v=%v", key, h.fields[key])
+	}
+	builder.WriteByte('}')
+	return builder.String()
+}
+
+// Format %+v 는 에러 chain 을 따라 에러별 stack 까지 출력. 그 외는 Error()
+func (h *NeptuneError) Format(state fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && state.Flag('+'):
+		h.writeDetail(state)
+	case verb == 'q':
+		_, _ = fmt.Fprintf(state, "%q", h.Error())
+	default:
+		_, _ = io.WriteString(state, h.Error())
+	}
+}
+
+func (h *NeptuneError) writeDetail(writer io.Writer) {
+	_, _ = fmt.Fprintf(writer, "[%v] %v (action:%v, category:%v)", h.code, h.message, h.action, h.Category())
+	if len(h.fields) > 0 {
+		_, _ = fmt.Fprintf(writer, " fields:%v", h.fieldString())
+	}
+	for _, frame := range h.StackTrace() {
+		_, _ = fmt.Fprintf(writer, "\n\tat %v", frame)
+	}
+	if h.err == nil {
+		return
+	}
+	_, _ = io.WriteString(writer, "\ncaused by: ")
+	if formatter, ok := h.err.(fmt.Formatter); ok {
+		_, _ = fmt.Fprintf(writer, "%+v", formatter)
+	} else {
+		_, _ = io.WriteString(writer, h.err.Error())
+		// fmt.Errorf("... : %w", neptuneError) 처럼 감싼 경우
+		if wrapped, ok := h.err.(interface{ Unwrap() error }); ok && wrapped.Unwrap() != nil {
+			if formatter, ok := wrapped.Unwrap().(fmt.Formatter); ok {
+				_, _ = fmt.Fprintf(writer, "\ncaused by: %+v", formatter)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrorList 여러 에러를 모은 에러. errors.Is, errors.As 는 각 에러에 대해 확인한다
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	messages := make([]string, 0, len(l))
+	for _, err := range l {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d errors : [%v]", len(l), strings.Join(messages, "; "))
+}
+
+func (l ErrorList) Is(target error) bool {
+	for _, err := range l {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l ErrorList) As(target interface{}) bool {
+	for _, err := range l {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Format %+v 는 각 에러를 %+v 로 출력
+func (l ErrorList) Format(state fmt.State, verb rune) {
+	if verb != 'v' || !state.Flag('+') {
+		_, _ = io.WriteString(state, l.Error())
+		return
+	}
+	_, _ = fmt.Fprintf(state, "%d errors", len(l))
+	for index, err := range l {
+		_, _ = fmt.Fprintf(state, "\n--- error %d ---\n%+v", index, err)
+	}
+}
+
+// JoinErrors nil 을 제외한 에러들을 하나의 NeptuneError 로. 없으면 nil, 하나면 AsNeptuneError, 여러 개면 ErrorList 를 감싼 ErrMultiple.
+// ErrMultiple 은 모든 에러가 재시도 가능할 때만 ActionRetry 이며, 모든 에러의 category 가 같으면 그 category 를 사용한다
+func JoinErrors(errs ...error) error {
+
+	var errorList ErrorList
+	for _, err := range errs {
+		if err != nil {
+			errorList = append(errorList, err)
+		}
+	}
+
+	switch len(errorList) {
+	case 0:
+		return nil
+	case 1:
+		return AsNeptuneError(errorList[0])
+	}
+
+	retryable := true
+	category := AsNeptuneError(errorList[0]).Category()
+	for _, err := range errorList {
+		neptuneError := AsNeptuneError(err)
+		retryable = retryable && neptuneError.Retryable()
+		if neptuneError.Category() != category {
+			category = CategoryInternal
+		}
+	}
+
+	joined := ErrMultiple.Copy(errorList).SetCategory(category)
+	if retryable {
+		joined.SetAction(ActionRetry)
+	}
+	return joined
+}
+
+// ErrorsOf JoinErrors 로 모은 에러 목록. ErrorList 가 아니면 err 하나
+func ErrorsOf(err error) []error {
+	if err == nil {
+		return nil
+	}
+	var errorList ErrorList
+	if neptuneError, ok := err.(*NeptuneError); ok && neptuneError.code == ErrMultipleCode {
+		if list, ok := neptuneError.err.(ErrorList); ok {
+			errorList = list
+		}
+	} else if list, ok := err.(ErrorList); ok {
+		errorList = list
+	}
+	if errorList == nil {
+		return []error{err}
+	}
+	return append([]error(nil), errorList...)
+}
+
+// JoinResultErrors GoRoutineWithContext 결과 중 error 값을 JoinErrors 로 모은다
+func JoinResultErrors(results []interface{}) error {
+	var errs []error
+	for _, result := range results {
+		if err, ok := result.(error); ok {
+			errs = append(errs, err)
+		}
+	}
+	return JoinErrors(errs...)
+}
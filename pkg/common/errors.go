@@ -1,15 +1,48 @@
 package common
 
-import "fmt"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	ActionRetry = "retry"
+	ActionAbort = "abort"
+)
+
+// 에러 분류. 전송 계층 (HTTP status, gRPC code) 변환에 사용
+const (
+	CategoryInvalidArgument   = "invalid_argument"
+	CategoryNotFound          = "not_found"
+	CategoryTimeout           = "timeout"
+	CategoryCanceled          = "canceled"
+	CategoryUnavailable       = "unavailable"
+	CategoryResourceExhausted = "resource_exhausted"
+	CategoryInternal          = "internal"
+)
 
 type NeptuneError struct {
-	code    int
-	message string
-	action  string
-	err     error
+	code     int
+	message  string
+	action   string
+	category string
+	err      error
+
+	// WithField 로 추가한 context 정보
+	fields map[string]interface{}
+
+	// 생성 (New, Copy, WithStack) 위치. EnableErrorStack 으로 켠 경우에만 저장
+	stack []uintptr
 }
 
 func (h *NeptuneError) Error() string {
+	if len(h.fields) > 0 {
+		return fmt.Sprintf("message:%v,  code:%v,  action:%v, fields:%v, err:%v", h.message, h.code, h.action, h.fieldString(), h.err)
+	}
 	return fmt.Sprintf("message:%v,  code:%v,  action:%v, err:%v", h.message, h.code, h.action, h.err)
 }
 
@@ -29,6 +62,34 @@ func (h *NeptuneError) SysErr() error {
 	return h.err
 }
 
+// Category 지정하지 않았으면 CategoryInternal
+func (h *NeptuneError) Category() string {
+	if h.category == "" {
+		return CategoryInternal
+	}
+	return h.category
+}
+
+// Retryable action 이 ActionRetry 인지 여부
+func (h *NeptuneError) Retryable() bool {
+	return h.action == ActionRetry
+}
+
+func (h *NeptuneError) Unwrap() error {
+	return h.err
+}
+
+// Is 같은 code 의 NeptuneError 이면 true (errors.Is(err, ErrSigTerm) 처럼 Copy 된 에러도 비교 가능)
+func (h *NeptuneError) Is(target error) bool {
+	neptuneError, ok := target.(*NeptuneError)
+	return ok && neptuneError.code == h.code
+}
+
+func (h *NeptuneError) SetCategory(category string) *NeptuneError {
+	h.category = category
+	return h
+}
+
 func (h *NeptuneError) SetAction(action string) *NeptuneError {
 	h.action = action
 	return h
@@ -39,12 +100,16 @@ func (h *NeptuneError) SetMessage(message string) *NeptuneError {
 	return h
 }
 
+// Copy err 를 감싼 새 에러. fields 는 복사되며 stack 은 Copy 를 호출한 위치로 새로 저장된다
 func (h *NeptuneError) Copy(err error) *NeptuneError {
 	return &NeptuneError{
-		code:    h.code,
-		message: h.message,
-		action:  h.action,
-		err:     err,
+		code:     h.code,
+		message:  h.message,
+		action:   h.action,
+		category: h.category,
+		err:      err,
+		fields:   h.copyFields(0),
+		stack:    captureStack(),
 	}
 }
 
@@ -53,13 +118,153 @@ func New(code int, message string, action string) *NeptuneError {
 		code:    code,
 		message: message,
 		action:  action,
+		stack:   captureStack(),
 	}
 }
 
 const (
-	ErrSigTermCode = 9
+	ErrUnknownCode  = 1
+	ErrMultipleCode = 2
+	ErrTimeoutCode  = 3
+	ErrSigTermCode  = 9
+
+	// external program execution
+	ErrExecNotFoundCode      = 101
+	ErrExecTimeoutCode       = 102
+	ErrExecNonZeroExitCode   = 103
+	ErrExecKilledCode        = 104
+	ErrExecFailedCode        = 105
+	ErrProcessPoolClosedCode = 106
+
+	// media
+	ErrMediaProbeCode = 111
+
+	// config
+	ErrConfigInvalidCode = 121
 )
 
 var (
-	ErrSigTerm = New(ErrSigTermCode, "signal term", "retry")
+	ErrUnknown  = New(ErrUnknownCode, "unknown error", ActionAbort)
+	ErrMultiple = New(ErrMultipleCode, "multiple errors", ActionAbort)
+	ErrTimeout  = New(ErrTimeoutCode, "timed out", ActionRetry).SetCategory(CategoryTimeout)
+	ErrSigTerm  = New(ErrSigTermCode, "signal term", ActionRetry).SetCategory(CategoryCanceled)
+
+	ErrExecNotFound      = New(ErrExecNotFoundCode, "external program not found", ActionAbort)
+	ErrExecTimeout       = New(ErrExecTimeoutCode, "external program timed out", ActionRetry).SetCategory(CategoryTimeout)
+	ErrExecNonZeroExit   = New(ErrExecNonZeroExitCode, "external program exited with non-zero code", ActionAbort)
+	ErrExecKilled        = New(ErrExecKilledCode, "external program killed by signal", ActionRetry)
+	ErrExecFailed        = New(ErrExecFailedCode, "external program execution failed", ActionAbort)
+	ErrProcessPoolClosed = New(ErrProcessPoolClosedCode, "process pool closed", ActionRetry).SetCategory(CategoryUnavailable)
+
+	ErrMediaProbe = New(ErrMediaProbeCode, "media probe output is invalid", ActionAbort).SetCategory(CategoryInvalidArgument)
+
+	ErrConfigInvalid = New(ErrConfigInvalidCode, "invalid configuration", ActionAbort).SetCategory(CategoryInvalidArgument)
 )
+
+func init() {
+	RegisterErrors(ErrUnknown, ErrMultiple, ErrTimeout, ErrSigTerm,
+		ErrExecNotFound, ErrExecTimeout, ErrExecNonZeroExit, ErrExecKilled, ErrExecFailed, ErrProcessPoolClosed,
+		ErrMediaProbe, ErrConfigInvalid)
+}
+
+// errorRegistry code 별로 등록된 에러 (gRPC status, JSON 등에서 복원할 때 사용)
+var errorRegistry = struct {
+	sync.RWMutex
+	errors map[int]*NeptuneError
+}{errors: map[int]*NeptuneError{}}
+
+// RegisterErrors 서비스별 에러를 등록. 같은 code 는 나중에 등록한 것으로 대체된다
+func RegisterErrors(neptuneErrors ...*NeptuneError) {
+	errorRegistry.Lock()
+	defer errorRegistry.Unlock()
+	for _, neptuneError := range neptuneErrors {
+		errorRegistry.errors[neptuneError.code] = neptuneError
+	}
+}
+
+// LookupError code 로 등록된 에러 조회
+func LookupError(code int) (*NeptuneError, bool) {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	neptuneError, ok := errorRegistry.errors[code]
+	return neptuneError, ok
+}
+
+// RegisteredErrors code 순서로 정렬된 등록 에러 목록
+func RegisteredErrors() []*NeptuneError {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	neptuneErrors := make([]*NeptuneError, 0, len(errorRegistry.errors))
+	for _, neptuneError := range errorRegistry.errors {
+		neptuneErrors = append(neptuneErrors, neptuneError)
+	}
+	sort.Slice(neptuneErrors, func(i, j int) bool {
+		return neptuneErrors[i].code < neptuneErrors[j].code
+	})
+	return neptuneErrors
+}
+
+// AsNeptuneError err chain 의 NeptuneError 반환. 없으면 context 에러는 ErrSigTerm, ErrTimeout 으로, 그 외는 ErrUnknown 으로 감싼다
+func AsNeptuneError(err error) *NeptuneError {
+	if err == nil {
+		return nil
+	}
+	var neptuneError *NeptuneError
+	switch {
+	case errors.As(err, &neptuneError):
+		return neptuneError
+	case errors.Is(err, context.Canceled):
+		return ErrSigTerm.Copy(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout.Copy(err)
+	default:
+		return ErrUnknown.Copy(err)
+	}
+}
+
+// IsRetryable err chain 의 NeptuneError 의 action 이 ActionRetry 이면 true
+func IsRetryable(err error) bool {
+	var neptuneError *NeptuneError
+	return errors.As(err, &neptuneError) && neptuneError.Retryable()
+}
+
+// neptuneErrorJson NeptuneError 의 JSON 형식
+type neptuneErrorJson struct {
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	Action    string `json:"action"`
+	Category  string `json:"category"`
+	Retryable bool   `json:"retryable"`
+	Error     string `json:"error,omitempty"`
+
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+func (h *NeptuneError) MarshalJSON() ([]byte, error) {
+	errorJson := &neptuneErrorJson{
+		Code:      h.code,
+		Message:   h.message,
+		Action:    h.action,
+		Category:  h.Category(),
+		Retryable: h.Retryable(),
+		Fields:    h.fields,
+	}
+	if h.err != nil {
+		errorJson.Error = h.err.Error()
+	}
+	return json.Marshal(errorJson)
+}
+
+// UnmarshalJSON 원래 에러는 문자열로만 복원되며 stack 은 복원되지 않는다
+func (h *NeptuneError) UnmarshalJSON(data []byte) error {
+	var errorJson neptuneErrorJson
+	if err := json.Unmarshal(data, &errorJson); err != nil {
+		return err
+	}
+	h.code, h.message, h.action, h.category = errorJson.Code, errorJson.Message, errorJson.Action, errorJson.Category
+	h.err, h.fields, h.stack = nil, errorJson.Fields, nil
+	if errorJson.Error != "" {
+		h.err = errors.New(errorJson.Error)
+	}
+	return nil
+}
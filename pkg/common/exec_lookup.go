@@ -0,0 +1,70 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// execPathCache 실행 파일 검색 결과 (key : 이름 + 검색 경로)
+var execPathCache sync.Map
+
+// LookupExecPath 실행 파일 경로 검색. execName 에 경로 구분자가 있으면 그 경로를, 없으면 searchPaths 의 디렉토리를
+// 순서대로 찾은 후 PATH 에서 찾는다. 결과는 캐시되며, 캐시된 파일이 삭제되면 다시 찾는다.
+// 찾지 못하면 exec.ErrNotFound 를 감싼 에러 반환
+func LookupExecPath(execName string, searchPaths ...string) (string, error) {
+
+	key := strings.Join(append([]string{execName}, searchPaths...), string(os.PathListSeparator))
+	if cached, ok := execPathCache.Load(key); ok {
+		if _, err := os.Stat(cached.(string)); err == nil {
+			return cached.(string), nil
+		}
+		execPathCache.Delete(key)
+	}
+
+	execPath, err := lookupExecPath(execName, searchPaths)
+	if err != nil {
+		return "", err
+	}
+	execPathCache.Store(key, execPath)
+	return execPath, nil
+}
+
+// ClearExecPathCache LookupExecPath 캐시 삭제 (실행 파일 교체 등)
+func ClearExecPathCache() {
+	execPathCache.Range(func(key, _ interface{}) bool {
+		execPathCache.Delete(key)
+		return true
+	})
+}
+
+func lookupExecPath(execName string, searchPaths []string) (string, error) {
+
+	if execName == "" {
+		return "", fmt.Errorf("empty program name : %w", exec.ErrNotFound)
+	}
+
+	// explicit path
+	if strings.ContainsRune(execName, os.PathSeparator) || strings.ContainsRune(execName, '/') {
+		execPath, err := exec.LookPath(execName)
+		if err != nil {
+			return "", fmt.Errorf("%v is not executable : %w", execName, err)
+		}
+		return filepath.Abs(execPath)
+	}
+
+	for _, dir := range searchPaths {
+		if execPath, err := exec.LookPath(filepath.Join(dir, execName)); err == nil {
+			return filepath.Abs(execPath)
+		}
+	}
+
+	execPath, err := exec.LookPath(execName)
+	if err != nil {
+		return "", fmt.Errorf("%v not found (search paths:%v, PATH) : %w", execName, searchPaths, exec.ErrNotFound)
+	}
+	return execPath, nil
+}
@@ -0,0 +1,113 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// writeProgram dir 에 name 파일 생성 (executable 이면 실행 권한 부여)
+func writeProgram(t *testing.T, dir string, name string, executable bool) string {
+	t.Helper()
+	mode := os.FileMode(0644)
+	if executable {
+		mode = 0755
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLookupExecPath(t *testing.T) {
+
+	first, second := t.TempDir(), t.TempDir()
+	program := writeProgram(t, second, "neptune-lookup", true)
+	writeProgram(t, first, "neptune-lookup", false)
+	shell, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not in PATH")
+	}
+
+	tests := []struct {
+		name        string
+		execName    string
+		searchPaths []string
+		want        string
+		wantErr     error
+	}{
+		{name: "search paths in order, skip not executable", execName: "neptune-lookup", searchPaths: []string{first, second}, want: program},
+		{name: "explicit path", execName: program, want: program},
+		{name: "PATH fallback", execName: "sh", searchPaths: []string{first}, want: shell},
+		{name: "not found", execName: "neptune-lookup", searchPaths: []string{first}, wantErr: exec.ErrNotFound},
+		{name: "explicit path not executable", execName: filepath.Join(first, "neptune-lookup"), wantErr: os.ErrPermission},
+		{name: "empty name", execName: "", wantErr: exec.ErrNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := LookupExecPath(test.execName, test.searchPaths...)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("LookupExecPath() error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("LookupExecPath() = %v, %v, want %v", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestLookupExecPathCache(t *testing.T) {
+
+	first, second := t.TempDir(), t.TempDir()
+	firstProgram := writeProgram(t, first, "neptune-cached", true)
+	secondProgram := writeProgram(t, second, "neptune-cached", true)
+
+	if got, err := LookupExecPath("neptune-cached", first, second); err != nil || got != firstProgram {
+		t.Fatalf("LookupExecPath() = %v, %v, want %v", got, err, firstProgram)
+	}
+
+	// 캐시된 파일이 삭제되면 다시 찾는다
+	if err := os.Remove(firstProgram); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LookupExecPath("neptune-cached", first, second); err != nil || got != secondProgram {
+		t.Fatalf("LookupExecPath() after remove = %v, %v, want %v", got, err, secondProgram)
+	}
+
+	// 캐시된 파일이 있으면 새로 생긴 파일은 ClearExecPathCache 후에 찾는다
+	writeProgram(t, first, "neptune-cached", true)
+	if got, _ := LookupExecPath("neptune-cached", first, second); got != secondProgram {
+		t.Errorf("LookupExecPath() = %v, want cached %v", got, secondProgram)
+	}
+	ClearExecPathCache()
+	if got, _ := LookupExecPath("neptune-cached", first, second); got != firstProgram {
+		t.Errorf("LookupExecPath() after ClearExecPathCache = %v, want %v", got, firstProgram)
+	}
+}
+
+func TestExecutorSearchPaths(t *testing.T) {
+
+	dir := t.TempDir()
+	program := writeProgram(t, dir, "neptune-search", true)
+	if err := ioutil.WriteFile(program, []byte("#!/bin/sh\necho found\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := NewExternalProgramExecutor("neptune-search", nil, nil)
+	executor.SearchPaths = []string{dir}
+	if output, err := executor.ExecuteContext(context.Background()); err != nil || output != "found\n" {
+		t.Errorf("ExecuteContext() = %q, %v, want %q", output, err, "found\n")
+	}
+}
@@ -0,0 +1,346 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// hangCheckInterval HangTimeout 확인 주기
+const hangCheckInterval = time.Second
+
+var (
+	errHangTimeout = errors.New("hang timeout")
+	errKilled      = fmt.Errorf("killed by caller : %w", context.Canceled)
+	errLimitSetup  = errors.New("resource limits setup failed")
+)
+
+// Execution ExternalProgramExecutor.Start 로 시작된 실행 handle. 종료는 Done 이 닫히는 것으로 한번만 알린다
+type Execution struct {
+	executor *ExternalProgramExecutor
+	command  *exec.Cmd
+	result   *ExecutionResult
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// 강제 종료 원인 (ctx 종료, hang timeout, Kill). 처음 설정된 값만 유지
+	mutex sync.Mutex
+	cause error
+
+	// process 종료
+	exited chan struct{}
+
+	// 실행 결과 확정
+	done chan struct{}
+	err  error
+
+	// 동기 실행 (Run)
+	outputBuffer *bytes.Buffer
+	errorBuffer  *tailBuffer
+
+	// 비동기 실행 (Start)
+	stdOutPipe io.ReadCloser
+	stdErrPipe io.ReadCloser
+	stdInPipe  io.WriteCloser
+
+	// Start 후 닫을 parent 쪽 pipe
+	closeAfterStart []io.Closer
+	cleanup         func()
+
+	// 자원 제한 설정 실패를 전달받는 pipe (Limits 가 없으면 nil)
+	limitFailure *os.File
+}
+
+// start capture 가 true 이면 stdout 과 stderr (마지막 StderrTailSize) 를 ExecutionResult 에 저장하고, 아니면 pipe 로 연결.
+// watchHang 이 true 이면 HangTimeout 동안 진행이 없을 때 종료한다
+func (e *ExternalProgramExecutor) start(ctx context.Context, capture bool, watchHang bool) (*Execution, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	arguments := e.arguments.getArguments()
+
+	execBin, err := e.findExecPath()
+	if err != nil {
+		logger.Error("external program not available (not installed)", "program", e.execName, "err", err)
+		e.execution = nil
+		e.result = &ExecutionResult{Command: append([]string{e.execName}, arguments...), StartTime: time.Now()}
+		e.result.finish(nil)
+		return nil, ErrExecNotFound.Copy(&ExecutionError{Result: e.result, Err: err})
+	}
+
+	logger.Info("external program command", "path", execBin, "args", arguments)
+
+	execution := &Execution{
+		executor: e,
+		command:  exec.Command(execBin, arguments...),
+		result: &ExecutionResult{
+			Command:   append([]string{execBin}, arguments...),
+			StartTime: time.Now(),
+		},
+		exited: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	execution.ctx, execution.cancel = context.WithCancel(ctx)
+	e.process = execution.command
+	e.result = execution.result
+	e.execution = execution
+
+	execution.cleanup, err = e.prepare(execution)
+	if err == nil {
+		if capture {
+			execution.outputBuffer = &bytes.Buffer{}
+			execution.errorBuffer = newTailBuffer(e.StderrTailSize)
+			execution.command.Stdout = execution.outputBuffer
+			if e.Stdout != nil {
+				execution.command.Stdout = e.Stdout
+			}
+			execution.command.Stderr = execution.errorBuffer
+		} else {
+			err = execution.openPipes()
+		}
+	}
+
+	// set latestTime with current time
+	atomic.StoreInt64(&e.latestTime, time.Now().Unix())
+
+	if err == nil {
+		err = startCommand(execution.command, e.Nice)
+	}
+	for _, closer := range execution.closeAfterStart {
+		_ = closer.Close()
+	}
+	if err != nil {
+		logger.Error("external program start error", "program", e.execName, "err", err)
+		execution.closePipes()
+		execution.cleanup()
+		execution.cancel()
+		execution.result.finish(nil)
+		execution.err = e.executionError(execution.result, fmt.Errorf("execution start fail (cmd:%v, err:%w)", arguments, err), ctx.Err())
+		close(execution.done)
+		return nil, execution.err
+	}
+
+	e.stdOutPipe, e.stdErrPipe, e.stdInPipe = execution.stdOutPipe, execution.stdErrPipe, execution.stdInPipe
+
+	go execution.wait(watchHang)
+
+	return execution, nil
+}
+
+// openPipes stdout, stderr 는 os.Pipe 로 연결 (exec.Cmd.StdoutPipe 는 Wait 에서 닫혀 남은 출력을 잃을 수 있음)
+func (x *Execution) openPipes() error {
+
+	if x.executor.Stdout != nil {
+		x.command.Stdout = x.executor.Stdout
+	} else {
+		stdOutReader, stdOutWriter, err := os.Pipe()
+		if err != nil {
+			return fmt.Errorf("stdout not available : %w", err)
+		}
+		x.command.Stdout, x.stdOutPipe = stdOutWriter, stdOutReader
+		x.closeAfterStart = append(x.closeAfterStart, stdOutWriter)
+	}
+
+	stdErrReader, stdErrWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("stderr not available : %w", err)
+	}
+	x.command.Stderr, x.stdErrPipe = stdErrWriter, stdErrReader
+	x.closeAfterStart = append(x.closeAfterStart, stdErrWriter)
+
+	if x.command.Stdin == nil {
+		stdIn, err := x.command.StdinPipe()
+		if err != nil {
+			return fmt.Errorf("stdin not available : %w", err)
+		}
+		x.stdInPipe = stdIn
+	}
+	return nil
+}
+
+func (x *Execution) closePipes() {
+	for _, closer := range []io.Closer{x.stdOutPipe, x.stdErrPipe, x.stdInPipe} {
+		if closer != nil {
+			_ = closer.Close()
+		}
+	}
+}
+
+// wait process 종료 후 결과를 확정하고 done 을 닫는다
+func (x *Execution) wait(watchHang bool) {
+
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		x.watch(watchHang)
+	}()
+
+	err := x.command.Wait()
+	close(x.exited)
+	<-watchDone
+	if limitErr := x.readLimitFailure(); limitErr != nil {
+		err = limitErr
+	}
+	x.cleanup()
+
+	x.result.finish(x.command.ProcessState)
+	if x.outputBuffer != nil {
+		x.result.Stdout = x.outputBuffer.String()
+		x.result.Stderr = x.errorBuffer.String()
+	}
+
+	x.mutex.Lock()
+	cause := x.cause
+	x.mutex.Unlock()
+
+	x.err = x.executor.executionError(x.result, err, cause)
+	if x.err != nil {
+		logger.Error("external program exec error", "program", x.executor.execName, "err", x.err)
+	}
+
+	x.cancel()
+	close(x.done)
+}
+
+// readLimitFailure 자원 제한 설정에 실패했으면 errLimitSetup. shell 이 종료 (혹은 exec) 되면 writer 가 모두 닫히므로 block 되지 않는다
+func (x *Execution) readLimitFailure() error {
+	if x.limitFailure == nil {
+		return nil
+	}
+	message, err := ioutil.ReadAll(x.limitFailure)
+	if err != nil || len(message) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w (%v)", errLimitSetup, strings.TrimSpace(string(message)))
+}
+
+// watch ctx 종료, Kill, HangTimeout 감시. process 가 종료되면 반환
+func (x *Execution) watch(watchHang bool) {
+
+	ticker := time.NewTicker(hangCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-x.exited:
+			return
+		case <-x.ctx.Done():
+			x.setCause(x.ctx.Err())
+			x.terminate(fmt.Sprintf("context done (%v)", x.Cause()))
+			return
+		case <-ticker.C:
+			hangTimeout := x.executor.HangTimeout
+			if !watchHang || hangTimeout <= 0 {
+				continue
+			}
+			if atomic.LoadInt64(&x.executor.latestTime)+hangTimeout <= time.Now().Unix() {
+				logger.Warn("external program timed out", "program", x.executor.execName, "hang_timeout", hangTimeout)
+				x.setCause(fmt.Errorf("no progress for %v seconds : %w", hangTimeout, errHangTimeout))
+				x.terminate("hang timeout")
+				return
+			}
+		}
+	}
+}
+
+// terminate process group 에 SIGTERM 을 보내고 KillGracePeriod 안에 종료되지 않으면 SIGKILL
+func (x *Execution) terminate(reason string) {
+
+	execName := x.executor.execName
+	logger.Info("external program terminating", "program", execName, "reason", reason)
+	if err := signalProcessGroup(x.command, syscall.SIGTERM); err != nil {
+		logger.Warn("external program SIGTERM error", "program", execName, "err", err)
+	}
+
+	gracePeriod := x.executor.KillGracePeriod
+	select {
+	case <-x.exited:
+		return
+	case <-time.After(gracePeriod):
+	}
+
+	logger.Warn("external program did not exit in grace period. send SIGKILL", "program", execName, "grace_period", gracePeriod)
+	if err := signalProcessGroup(x.command, syscall.SIGKILL); err != nil {
+		logger.Warn("external program SIGKILL error", "program", execName, "err", err)
+	}
+}
+
+// setCause process 가 이미 종료되었으면 무시 (정상 종료 후의 Kill 이 Cause 와 Wait 의 에러를 다르게 만들지 않도록)
+func (x *Execution) setCause(cause error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	select {
+	case <-x.exited:
+		return
+	default:
+	}
+	if x.cause == nil {
+		x.cause = cause
+	}
+}
+
+// Cause 강제 종료 원인 (정상 종료면 nil). hang timeout 과 ctx deadline 은 ErrExecTimeout, Kill 은 ErrExecKilled, ctx 취소는 ErrSigTerm
+func (x *Execution) Cause() error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	if x.cause == nil {
+		return nil
+	}
+	return causeError(x.cause).Copy(x.cause)
+}
+
+// Wait 종료될 때까지 기다린 후 실행 결과 반환 (여러번, 여러 goroutine 에서 호출 가능)
+func (x *Execution) Wait() (*ExecutionResult, error) {
+	<-x.done
+	return x.result, x.err
+}
+
+// Done 실행 결과가 확정되면 닫힌다
+func (x *Execution) Done() <-chan struct{} {
+	return x.done
+}
+
+// Kill process group 종료 (SIGTERM, KillGracePeriod 후 SIGKILL). 결과는 ErrExecKilled 이며 이미 종료된 후에는 아무것도 하지 않는다
+func (x *Execution) Kill() {
+	x.kill(errKilled)
+}
+
+func (x *Execution) kill(cause error) {
+	x.setCause(cause)
+	x.cancel()
+}
+
+func (x *Execution) Pid() int {
+	return x.command.Process.Pid
+}
+
+// Result 실행 결과. Done 이 닫히기 전에는 종료 정보가 채워지지 않는다
+func (x *Execution) Result() *ExecutionResult {
+	return x.result
+}
+
+// StdOutPipe ExternalProgramExecutor.Stdout 이 설정된 경우 nil
+func (x *Execution) StdOutPipe() io.ReadCloser {
+	return x.stdOutPipe
+}
+
+func (x *Execution) StdErrPipe() io.ReadCloser {
+	return x.stdErrPipe
+}
+
+// StdInPipe Stdin, StdinFile 이 설정된 경우 nil
+func (x *Execution) StdInPipe() io.WriteCloser {
+	return x.stdInPipe
+}
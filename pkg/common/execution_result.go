@@ -0,0 +1,118 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStderrTailSize ExecutionResult 에 보관하는 stderr 의 최대 크기 (마지막 부분)
+const DefaultStderrTailSize = 64 * KB
+
+// ExecutionResult 외부 프로그램 실행 결과
+type ExecutionResult struct {
+	Command   []string      `json:"command"`
+	Stdout    string        `json:"stdout,omitempty"`
+	Stderr    string        `json:"stderr,omitempty"`
+	ExitCode  int           `json:"exit_code"`
+	Signal    string        `json:"signal,omitempty"`
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Duration  time.Duration `json:"duration"`
+
+	// 최대 메모리 사용량 (bytes)
+	PeakRSS int64 `json:"peak_rss"`
+
+	UserTime   time.Duration `json:"user_time"`
+	SystemTime time.Duration `json:"system_time"`
+}
+
+func (r *ExecutionResult) CPUTime() time.Duration {
+	return r.UserTime + r.SystemTime
+}
+
+func (r *ExecutionResult) finish(state *os.ProcessState) {
+	r.EndTime = time.Now()
+	r.Duration = r.EndTime.Sub(r.StartTime)
+	if state == nil {
+		r.ExitCode = -1
+		return
+	}
+	r.ExitCode = state.ExitCode()
+	r.Signal = exitSignal(state)
+	r.PeakRSS = peakRSS(state)
+	r.UserTime = state.UserTime()
+	r.SystemTime = state.SystemTime()
+}
+
+// ExecutionError 실행 실패 원인과 실행 결과. NeptuneError (ErrExecNotFound 등) 에 감싸져 반환된다
+type ExecutionError struct {
+	Result *ExecutionResult
+	Err    error
+}
+
+func (e *ExecutionError) Error() string {
+	if e.Result == nil {
+		return fmt.Sprintf("%v", e.Err)
+	}
+	return fmt.Sprintf("%v (exit_code:%v, signal:%v, stderr:%v)",
+		e.Err, e.Result.ExitCode, e.Result.Signal, lastLine(e.Result.Stderr))
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// ExecutionResultOf 실행 에러에서 실행 결과를 꺼낸다 (없으면 nil)
+func ExecutionResultOf(err error) *ExecutionResult {
+	var neptuneError *NeptuneError
+	if errors.As(err, &neptuneError) {
+		err = neptuneError.SysErr()
+	}
+	var executionError *ExecutionError
+	if errors.As(err, &executionError) {
+		return executionError.Result
+	}
+	return nil
+}
+
+func lastLine(text string) string {
+	text = strings.TrimRight(text, "\r\n")
+	if index := strings.LastIndexAny(text, "\r\n"); index >= 0 {
+		return text[index+1:]
+	}
+	return text
+}
+
+// tailBuffer 마지막 size bytes 만 보관하는 writer
+type tailBuffer struct {
+	mutex sync.Mutex
+	size  int
+	data  []byte
+}
+
+func newTailBuffer(size int) *tailBuffer {
+	if size <= 0 {
+		size = DefaultStderrTailSize
+	}
+	return &tailBuffer{size: size}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.data = append(t.data, p...)
+	if overflow := len(t.data) - t.size; overflow > 0 {
+		t.data = append(t.data[:0], t.data[overflow:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return string(t.data)
+}
@@ -0,0 +1,114 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestTailBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		writes []string
+		want   string
+	}{
+		{name: "under size", size: 8, writes: []string{"ab", "cd"}, want: "abcd"},
+		{name: "keeps last bytes", size: 4, writes: []string{"ab", "cdef", "g"}, want: "defg"},
+		{name: "single large write", size: 3, writes: []string{"abcdefgh"}, want: "fgh"},
+		{name: "empty", size: 4, want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tail := newTailBuffer(test.size)
+			for _, write := range test.writes {
+				if n, err := tail.Write([]byte(write)); n != len(write) || err != nil {
+					t.Fatalf("Write(%q) = %v, %v", write, n, err)
+				}
+			}
+			if got := tail.String(); got != test.want {
+				t.Errorf("String() = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	if tail := newTailBuffer(0); tail.size != DefaultStderrTailSize {
+		t.Errorf("newTailBuffer(0).size = %v, want %v", tail.size, DefaultStderrTailSize)
+	}
+}
+
+func TestRunResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		tailSize int
+		stdout   string
+		stderr   string
+		exitCode int
+		signal   string
+		want     *NeptuneError
+	}{
+		{name: "success", script: "echo out; echo err >&2", stdout: "out\n", stderr: "err\n"},
+		{name: "non-zero exit", script: "echo out; echo fail >&2; exit 3", stdout: "out\n", stderr: "fail\n", exitCode: 3, want: ErrExecNonZeroExit},
+		{name: "signal", script: "kill -9 $$", exitCode: -1, signal: syscall.SIGKILL.String(), want: ErrExecKilled},
+		{name: "stderr tail", script: "printf '0123456789' >&2; printf 'END' >&2", tailSize: 5, stderr: "89END"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor := NewExternalProgramExecutor("sh", []string{"-c", test.script}, nil)
+			if test.tailSize > 0 {
+				executor.StderrTailSize = test.tailSize
+			}
+			result, err := executor.Run(context.Background())
+
+			if test.want == nil && err != nil || test.want != nil && !errors.Is(err, test.want) {
+				t.Fatalf("Run() error = %v, want %v", err, test.want)
+			}
+			if result.Stdout != test.stdout || result.Stderr != test.stderr {
+				t.Errorf("Stdout, Stderr = %q, %q, want %q, %q", result.Stdout, result.Stderr, test.stdout, test.stderr)
+			}
+			if result.ExitCode != test.exitCode || result.Signal != test.signal {
+				t.Errorf("ExitCode, Signal = %v, %q, want %v, %q", result.ExitCode, result.Signal, test.exitCode, test.signal)
+			}
+			if result.Duration <= 0 || result.EndTime.Before(result.StartTime) {
+				t.Errorf("Duration = %v (%v ~ %v)", result.Duration, result.StartTime, result.EndTime)
+			}
+			if executor.GetResult() != result || executor.GetExitCode() != test.exitCode {
+				t.Errorf("GetResult() does not return the last result")
+			}
+			if err != nil {
+				if ExecutionResultOf(err) != result {
+					t.Errorf("ExecutionResultOf() does not return the result of Run")
+				}
+				if lastStderr := lastLine(test.stderr); lastStderr != "" && !strings.Contains(err.Error(), lastStderr) {
+					t.Errorf("error %q does not contain the last stderr line %q", err, lastStderr)
+				}
+			}
+		})
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "", want: ""},
+		{text: "one", want: "one"},
+		{text: "one\ntwo\n", want: "two"},
+		{text: "progress 10%\rprogress 20%\r", want: "progress 20%"},
+	}
+
+	for _, test := range tests {
+		if got := lastLine(test.text); got != test.want {
+			t.Errorf("lastLine(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
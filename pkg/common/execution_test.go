@@ -0,0 +1,343 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess 테스트에서 실행하는 가짜 외부 프로그램 (os.Args[0] 을 다시 실행).
+//
+//	sleep <seconds>         : 잠시 대기
+//	ignore-term <seconds>   : SIGTERM 을 무시하고 "ready" 출력 후 대기
+//	spawn <seconds>         : 같은 process group 에 sleep 자식을 만들고 그 pid 출력 후 대기
+//	exit <code>             : code 로 종료
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 3 {
+		fmt.Fprintln(os.Stderr, "usage : -- <command> <value>")
+		os.Exit(2)
+	}
+	value, _ := strconv.Atoi(args[2])
+
+	switch args[1] {
+	case "sleep":
+		time.Sleep(time.Duration(value) * time.Second)
+	case "ignore-term":
+		signal.Ignore(syscall.SIGTERM)
+		fmt.Println("ready")
+		time.Sleep(time.Duration(value) * time.Second)
+	case "spawn":
+		child := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep", args[2])
+		child.Stdout, child.Stderr = os.Stdout, os.Stderr
+		if err := child.Start(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Println(child.Process.Pid)
+		_ = child.Wait()
+	case "exit":
+		os.Exit(value)
+	}
+	os.Exit(0)
+}
+
+func helperExecutor(args ...string) *ExternalProgramExecutor {
+	executor := NewExternalProgramExecutor("helper", []string{"-test.run=TestHelperProcess", "--"}, args)
+	executor.ExecPath = os.Args[0]
+	executor.KillGracePeriod = 200 * time.Millisecond
+	executor.SetEnv("GO_WANT_HELPER_PROCESS", "1")
+	return executor
+}
+
+// waitExecution 테스트가 멈추지 않도록 timeout 을 두고 종료를 기다림
+func waitExecution(t *testing.T, execution *Execution) (*ExecutionResult, error) {
+	t.Helper()
+	select {
+	case <-execution.Done():
+	case <-time.After(10 * time.Second):
+		execution.Kill()
+		t.Fatal("execution did not finish")
+	}
+	return execution.Wait()
+}
+
+func TestExecutionCause(t *testing.T) {
+	tests := []struct {
+		name    string
+		execute func(t *testing.T) *Execution
+		want    *NeptuneError
+	}{
+		{
+			name: "ctx deadline",
+			execute: func(t *testing.T) *Execution {
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				t.Cleanup(cancel)
+				execution, err := helperExecutor("sleep", "10").Start(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return execution
+			},
+			want: ErrExecTimeout,
+		},
+		{
+			name: "hang timeout",
+			execute: func(t *testing.T) *Execution {
+				executor := helperExecutor("sleep", "10")
+				executor.HangTimeout = 1
+				execution, err := executor.Start(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				return execution
+			},
+			want: ErrExecTimeout,
+		},
+		{
+			name: "ctx cancel",
+			execute: func(t *testing.T) *Execution {
+				ctx, cancel := context.WithCancel(context.Background())
+				execution, err := helperExecutor("sleep", "10").Start(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				time.AfterFunc(100*time.Millisecond, cancel)
+				return execution
+			},
+			want: ErrSigTerm,
+		},
+		{
+			name: "kill",
+			execute: func(t *testing.T) *Execution {
+				execution, err := helperExecutor("sleep", "10").Start(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				time.AfterFunc(100*time.Millisecond, execution.Kill)
+				return execution
+			},
+			want: ErrExecKilled,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			execution := test.execute(t)
+			_, err := waitExecution(t, execution)
+			if !errors.Is(execution.Cause(), test.want) {
+				t.Errorf("Cause() = %v, want code %v", execution.Cause(), test.want.Code())
+			}
+			if !errors.Is(err, test.want) {
+				t.Errorf("Wait() error = %v, want code %v", err, test.want.Code())
+			}
+		})
+	}
+}
+
+func TestExecutionNormalExit(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     *NeptuneError
+		exitCode int
+	}{
+		{name: "success", args: []string{"exit", "0"}},
+		{name: "non-zero exit", args: []string{"exit", "3"}, want: ErrExecNonZeroExit, exitCode: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			execution, err := helperExecutor(test.args...).Start(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := waitExecution(t, execution)
+			if execution.Cause() != nil {
+				t.Errorf("Cause() = %v, want nil", execution.Cause())
+			}
+			if test.want == nil && err != nil || test.want != nil && !errors.Is(err, test.want) {
+				t.Errorf("Wait() error = %v, want %v", err, test.want)
+			}
+			if result.ExitCode != test.exitCode {
+				t.Errorf("ExitCode = %v, want %v", result.ExitCode, test.exitCode)
+			}
+		})
+	}
+}
+
+func TestExecutionKillAfterExit(t *testing.T) {
+
+	execution, err := helperExecutor("exit", "0").Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := waitExecution(t, execution); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	// 정상 종료 후의 Kill 은 결과를 바꾸지 않는다
+	execution.Kill()
+	if cause := execution.Cause(); cause != nil {
+		t.Errorf("Cause() after Kill = %v, want nil", cause)
+	}
+	if _, err := execution.Wait(); err != nil {
+		t.Errorf("Wait() error after Kill = %v, want nil", err)
+	}
+}
+
+func TestExecutionDoneOnce(t *testing.T) {
+
+	execution, err := helperExecutor("sleep", "10").Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait, Kill 을 동시에 여러번 호출해도 결과는 하나
+	var waitGroup sync.WaitGroup
+	errs := make(chan error, 8)
+	for index := 0; index < cap(errs); index++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			execution.Kill()
+			_, err := execution.Wait()
+			errs <- err
+		}()
+	}
+	waitGroup.Wait()
+	close(errs)
+
+	var first error
+	for err := range errs {
+		if first == nil {
+			first = err
+		}
+		if err != first {
+			t.Errorf("Wait() returned different errors : %v, %v", first, err)
+		}
+	}
+	if !errors.Is(first, ErrExecKilled) {
+		t.Errorf("Wait() error = %v, want ErrExecKilled", first)
+	}
+
+	select {
+	case <-execution.Done():
+	default:
+		t.Fatal("Done() is not closed after Wait")
+	}
+	execution.Kill()
+
+	// ExecuteAsynchronouslyContext 는 한번만 전달하고 닫는다
+	done := helperExecutor("exit", "0").ExecuteAsynchronouslyContext(context.Background())
+	count := 0
+	timeout := time.After(10 * time.Second)
+	for open := true; open; {
+		select {
+		case err, ok := <-done:
+			if !ok {
+				open = false
+				break
+			}
+			count++
+			if err != nil {
+				t.Errorf("ExecuteAsynchronouslyContext error = %v", err)
+			}
+		case <-timeout:
+			t.Fatal("ExecuteAsynchronouslyContext did not finish")
+		}
+	}
+	if count != 1 {
+		t.Errorf("received %v results, want 1", count)
+	}
+}
+
+func TestExecutionKillEscalation(t *testing.T) {
+
+	execution, err := helperExecutor("ignore-term", "30").Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SIGTERM 을 무시하도록 설정된 후에 종료
+	reader := bufio.NewReader(execution.StdOutPipe())
+	if line, err := reader.ReadString('\n'); err != nil || line != "ready\n" {
+		t.Fatalf("helper output = %q, %v", line, err)
+	}
+
+	killed := time.Now()
+	execution.Kill()
+	result, err := waitExecution(t, execution)
+	elapsed := time.Since(killed)
+
+	if !errors.Is(err, ErrExecKilled) {
+		t.Errorf("Wait() error = %v, want ErrExecKilled", err)
+	}
+	if result.Signal != syscall.SIGKILL.String() {
+		t.Errorf("Signal = %q, want %q", result.Signal, syscall.SIGKILL.String())
+	}
+	if elapsed < execution.executor.KillGracePeriod {
+		t.Errorf("killed after %v, want at least the grace period %v", elapsed, execution.executor.KillGracePeriod)
+	}
+}
+
+func TestExecutionKillProcessGroup(t *testing.T) {
+
+	execution, err := helperExecutor("spawn", "30").Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(execution.StdOutPipe())
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("helper output = %q, %v", line, err)
+	}
+	grandchild, err := strconv.Atoi(line[:len(line)-1])
+	if err != nil {
+		t.Fatalf("grandchild pid = %q", line)
+	}
+
+	execution.Kill()
+	if _, err := waitExecution(t, execution); !errors.Is(err, ErrExecKilled) {
+		t.Errorf("Wait() error = %v, want ErrExecKilled", err)
+	}
+
+	// grandchild 가 stdout 을 물려받았으므로 grandchild 까지 종료되어야 EOF
+	eof := make(chan error, 1)
+	go func() {
+		_, err := ioutil.ReadAll(reader)
+		eof <- err
+	}()
+	select {
+	case err := <-eof:
+		if err != nil {
+			t.Errorf("stdout read error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		_ = syscall.Kill(grandchild, syscall.SIGKILL)
+		t.Fatalf("grandchild (pid:%v) is still running", grandchild)
+	}
+}
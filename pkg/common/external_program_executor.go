@@ -2,34 +2,81 @@ package common
 
 import (
 	"bufio"
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"os"
 	"os/exec"
-	"strings"
 	"sync/atomic"
 	"time"
 )
 
 const DefaultHangTimeout = 20 * 60
 
+// DefaultKillGracePeriod 취소/timeout 시 SIGTERM 이후 SIGKILL 을 보내기까지의 대기 시간
+const DefaultKillGracePeriod = 10 * time.Second
+
 type ExternalProgramExecutor struct {
-	execName   string
-	arguments  *ExternalProgramArguments
+	execName  string
+	arguments *ExternalProgramArguments
 
 	HangTimeout int64
 
+	// 실행 파일 경로. 비어 있으면 execName 을 SearchPaths, PATH 순서로 찾는다 (LookupExecPath)
+	ExecPath    string
+	SearchPaths []string
+
+	// 취소/timeout 시 process group 에 SIGTERM 을 보낸 후 SIGKILL 을 보내기까지의 대기 시간
+	KillGracePeriod time.Duration
+
+	// ExecutionResult.Stderr 에 보관할 최대 크기 (bytes)
+	StderrTailSize int
+
+	// 추가/재정의할 환경 변수. ClearEnv 가 false 이면 현재 프로세스의 환경 변수를 상속
+	Env      map[string]string
+	ClearEnv bool
+
+	// 작업 디렉토리 (비어 있으면 현재 디렉토리)
+	Dir string
+
+	// 표준 입력. Stdin 이 우선하며, 둘 다 없으면 비동기 실행시 GetStdInPipe 로 직접 입력
+	Stdin     io.Reader
+	StdinFile string
+
+	// 표준 출력. 설정하면 Run 의 ExecutionResult.Stdout 은 비어 있고, 비동기 실행시 StdOutPipe 는 nil
+	Stdout io.Writer
+
+	// 자원 제한 및 nice 값 (-20 ~ 19, 0 이면 변경하지 않음). linux 만 지원하며 프로그램이 실행되기 전에 적용된다.
+	// Limits 는 /bin/sh 의 ulimit 으로 설정하므로 /bin/sh 가 필요하며, 설정에 실패하면 ErrExecFailed
+	Limits *ResourceLimits
+	Nice   int
+
 	stdErrPipe io.ReadCloser
 	stdOutPipe io.ReadCloser
 	stdInPipe  io.WriteCloser
 	process    *exec.Cmd
 
-	done chan error
+	result *ExecutionResult
+
+	// 마지막 실행
+	execution *Execution
 
 	latestTime int64
 }
 
+// ResourceLimits 외부 프로그램 자원 제한 (0 이면 제한하지 않음)
+type ResourceLimits struct {
+	// CPU 사용 시간 (seconds)
+	CPUTime uint64 `json:"cpu_time"`
+
+	// 가상 메모리 크기 (bytes)
+	AddressSpace uint64 `json:"address_space"`
+
+	// 최대 open file 수
+	OpenFiles uint64 `json:"open_files"`
+}
+
 func NewExternalProgramExecutor(execName string, inputArgs []string, outputArgs []string) *ExternalProgramExecutor {
 	return &ExternalProgramExecutor{
 		execName: execName,
@@ -37,8 +84,19 @@ func NewExternalProgramExecutor(execName string, inputArgs []string, outputArgs
 			InputArgs:  inputArgs,
 			OutputArgs: outputArgs,
 		},
-		HangTimeout: DefaultHangTimeout,
+		HangTimeout:     DefaultHangTimeout,
+		KillGracePeriod: DefaultKillGracePeriod,
+		StderrTailSize:  DefaultStderrTailSize,
+	}
+}
+
+// SetEnv 환경 변수 추가/재정의
+func (e *ExternalProgramExecutor) SetEnv(key string, value string) *ExternalProgramExecutor {
+	if e.Env == nil {
+		e.Env = map[string]string{}
 	}
+	e.Env[key] = value
+	return e
 }
 
 func (e *ExternalProgramExecutor) GetStdErrPipe() io.ReadCloser {
@@ -53,8 +111,25 @@ func (e *ExternalProgramExecutor) GetStdInPipe() io.WriteCloser {
 	return e.stdInPipe
 }
 
+// GetExitCode 종료 코드. 실행 전이거나 종료되지 않은 경우 -1
 func (e *ExternalProgramExecutor) GetExitCode() int {
-	return e.process.ProcessState.ExitCode()
+	result := e.GetResult()
+	if result == nil {
+		return -1
+	}
+	return result.ExitCode
+}
+
+// GetResult 마지막 실행 결과 (실행 전이거나 종료되지 않은 경우 nil)
+func (e *ExternalProgramExecutor) GetResult() *ExecutionResult {
+	if e.execution != nil {
+		select {
+		case <-e.execution.Done():
+		default:
+			return nil
+		}
+	}
+	return e.result
 }
 
 func (e *ExternalProgramExecutor) GetProcess() *exec.Cmd {
@@ -62,136 +137,154 @@ func (e *ExternalProgramExecutor) GetProcess() *exec.Cmd {
 }
 
 func (e *ExternalProgramExecutor) ExecuteSynchronously() (string, error) {
+	return e.ExecuteContext(context.Background())
+}
 
-	execBin, err := findExecPath(e.execName)
+// ExecuteContext 동기 실행. ctx 가 취소되면 process group 전체를 종료 (SIGTERM, KillGracePeriod 후 SIGKILL)
+func (e *ExternalProgramExecutor) ExecuteContext(ctx context.Context) (string, error) {
+	result, err := e.Run(ctx)
 	if err != nil {
-		log.Printf("external program (%v) not available (not installed) : %v\n", e.execName, err.Error())
 		return "", err
 	}
+	return result.Stdout, nil
+}
 
-	// set command to exec execution
-	command := e.arguments.getArguments()
-	log.Printf("exeternal program command : %v %v", execBin, command)
-
-	var outputBuffer, errorBuffer bytes.Buffer
-	e.process = exec.Command(execBin, command...)
-	e.process.Stdout = &outputBuffer
-	e.process.Stderr = &errorBuffer
-
-	// exec execution
-	if err := e.process.Run(); err != nil {
-		log.Printf("%v exec error, err=%v", e.execName, err)
-		return "", err
+// Run 동기 실행 후 실행 결과 반환. 실패시 ExecutionError 를 감싼 NeptuneError (ErrExecNotFound,
+// ErrExecTimeout, ErrExecNonZeroExit, ErrExecKilled, ErrSigTerm, ErrExecFailed) 와 함께 결과를 반환
+func (e *ExternalProgramExecutor) Run(ctx context.Context) (*ExecutionResult, error) {
+	execution, err := e.start(ctx, true, false)
+	if err != nil {
+		return e.result, err
 	}
+	return execution.Wait()
+}
 
-	return outputBuffer.String(), nil
+// Start 비동기 실행. 표준 입출력은 Execution (혹은 GetStdOutPipe 등) 의 pipe 로 처리하며,
+// ctx 가 취소되거나 HangTimeout 동안 진행 (OnProgress 등) 이 없으면 process group 전체를 종료한다.
+// 실행 파일이 없거나 시작에 실패하면 Run 과 같은 NeptuneError 반환
+func (e *ExternalProgramExecutor) Start(ctx context.Context) (*Execution, error) {
+	return e.start(ctx, false, true)
 }
 
 func (e *ExternalProgramExecutor) ExecuteAsynchronously() <-chan error {
+	return e.ExecuteAsynchronouslyContext(context.Background())
+}
 
-	e.done = make(chan error)
+// ExecuteAsynchronouslyContext Start 후 종료 결과를 한번 전달하고 닫히는 channel 반환 (읽지 않아도 block 되지 않음)
+func (e *ExternalProgramExecutor) ExecuteAsynchronouslyContext(ctx context.Context) <-chan error {
 
-	execBin, err := findExecPath(e.execName)
+	done := make(chan error, 1)
+
+	execution, err := e.Start(ctx)
 	if err != nil {
-		log.Printf("external program (%v) not available (not installed) : %v", e.execName, err.Error())
+		done <- err
+		close(done)
+		return done
 	}
 
-	// set latestTime with current time
-	atomic.StoreInt64(&e.latestTime, time.Now().Unix())
+	go func() {
+		defer close(done)
+		_, err := execution.Wait()
+		done <- err
+	}()
 
-	// set command to exec ffmpeg
-	command := e.arguments.getArguments()
-	log.Printf("external program command : %v %v", execBin, command)
+	return done
+}
 
-	proc := exec.Command(execBin, command...)
-	errStream, err := proc.StderrPipe()
-	if err != nil {
-		log.Println("stderr not available : ", err.Error())
-	} else {
-		e.stdErrPipe = errStream
-	}
+// executionError 실패 원인별 NeptuneError 로 분류. cause 는 ctx 종료, hang timeout, Kill 등 강제 종료 원인
+func (e *ExternalProgramExecutor) executionError(result *ExecutionResult, err error, cause error) error {
 
-	stdOut, err := proc.StdoutPipe()
-	if err != nil {
-		log.Println("stdout not available : ", err.Error())
-	} else {
-		e.stdOutPipe = stdOut
+	if cause != nil {
+		return causeError(cause).Copy(&ExecutionError{Result: result, Err: cause})
 	}
-
-	stdIn, err := proc.StdinPipe()
-	if err != nil {
-		log.Println("stdin not available : ", err.Error())
-	} else {
-		e.stdInPipe = stdIn
+	if err == nil {
+		return nil
 	}
+	executionError := &ExecutionError{Result: result, Err: err}
+
+	switch {
+	case errors.Is(err, errLimitSetup):
+		return ErrExecFailed.Copy(executionError)
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, os.ErrNotExist):
+		return ErrExecNotFound.Copy(executionError)
+	case result.Signal != "":
+		return ErrExecKilled.Copy(executionError)
+	case result.ExitCode > 0:
+		return ErrExecNonZeroExit.Copy(executionError)
+	default:
+		return ErrExecFailed.Copy(executionError)
+	}
+}
 
-	// exec fmpeg
-	err = proc.Start()
-	e.process = proc
-	log.Printf("after proc start")
+// causeError 강제 종료 원인별 에러. hang timeout 과 ctx deadline 은 ErrExecTimeout, Kill 은 ErrExecKilled, 그 외 ctx 취소는 ErrSigTerm
+func causeError(cause error) *NeptuneError {
+	switch {
+	case errors.Is(cause, errHangTimeout), errors.Is(cause, context.DeadlineExceeded):
+		return ErrExecTimeout
+	case errors.Is(cause, errKilled):
+		return ErrExecKilled
+	default:
+		return ErrSigTerm
+	}
+}
 
-	go func(err error) {
+// prepare 자원 제한, 환경 변수, 작업 디렉토리, 표준 입력 설정. 반환된 함수로 열린 파일을 정리한다
+func (e *ExternalProgramExecutor) prepare(execution *Execution) (func(), error) {
 
-		defer func() {
-			close(e.done)
-		}()
+	command := execution.command
+	setProcessGroup(command)
 
-		if err != nil {
-			e.done <- fmt.Errorf("execution start fail (cmd:%v, err:%v)", command, err)
-			return
+	var opened []io.Closer
+	cleanup := func() {
+		for _, closer := range opened {
+			_ = closer.Close()
 		}
+	}
 
-		// on timer (10 minutes...)
-		done := e.OnTimeout()
-
-		defer func() {
-			close(done)
-		}()
-
-		log.Printf("begin to wait....")
-		err = proc.Wait()
-		if err != nil {
-			err = fmt.Errorf("failed finish execution. (cmd=%v) %w", command, err)
+	// 작업 디렉토리가 없으면 Start 는 실행 파일이 없는 것과 같은 에러를 반환하므로 미리 확인
+	if e.Dir != "" {
+		if info, err := os.Stat(e.Dir); err != nil || !info.IsDir() {
+			return cleanup, fmt.Errorf("working directory is not available (dir:%v)", e.Dir)
 		}
-		log.Printf("finish to wait....")
-
-		done <- err
-		e.done <- err
-
-	}(err)
-
-	return e.done
-}
+	}
+	command.Dir = e.Dir
 
-func (e *ExternalProgramExecutor) OnTimeout() chan error{
+	limitFailure, limitWriter, err := limitCommand(command, e.Limits)
+	if err != nil {
+		return cleanup, fmt.Errorf("resource limits error : %w", err)
+	}
+	if limitFailure != nil {
+		execution.limitFailure = limitFailure
+		execution.closeAfterStart = append(execution.closeAfterStart, limitWriter)
+		opened = append(opened, limitFailure)
+	}
 
-	done := make(chan error)
+	if e.ClearEnv || len(e.Env) > 0 {
+		// nil 이 아닌 빈 slice 로 설정해야 상속되지 않음
+		env := []string{}
+		if !e.ClearEnv {
+			env = os.Environ()
+		}
+		for key, value := range e.Env {
+			env = append(env, fmt.Sprintf("%v=%v", key, value))
+		}
+		command.Env = env
+	}
 
-	go func() {
-		for {
-			select {
-			case err, _ := <-done:
-				log.Printf("timeout checker ended.. (err=%v)", err)
-				return
-			default:
-				currentTime := time.Now().Unix()
-				latestTime := atomic.LoadInt64(&e.latestTime)
-				if latestTime + e.HangTimeout <= currentTime {
-					err := e.GetProcess().Process.Kill()
-					if err != nil {
-						log.Printf("external program (%v) termination waring (err=%v)\n", e.execName, err)
-					}
-					log.Printf("external program (%v) timed out", e.execName)
-					e.done <- ErrSigTerm.Copy(err)
-					return
-				} else {
-					time.Sleep(time.Second)
-				}
-			}
+	switch {
+	case e.Stdin != nil:
+		command.Stdin = e.Stdin
+	case e.StdinFile != "":
+		file, err := os.Open(e.StdinFile)
+		if err != nil {
+			// os.ErrNotExist 를 감싸면 ErrExecNotFound 로 분류되므로 감싸지 않는다
+			return cleanup, fmt.Errorf("stdin file open error (file:%v) : %v", e.StdinFile, err)
 		}
-	}()
+		command.Stdin = file
+		opened = append(opened, file)
+	}
 
-	return done
+	return cleanup, nil
 }
 
 func (e *ExternalProgramExecutor) OnProgress(splitFunction func([]byte, bool) (int, []byte, error),
@@ -214,7 +307,7 @@ func (e *ExternalProgramExecutor) OnProgress(splitFunction func([]byte, bool) (i
 
 		scanner := bufio.NewScanner(pipe)
 		scanner.Split(splitFunction)
-		scanner.Buffer(make([]byte, 2), bufio.MaxScanTokenSize)
+		scanner.Buffer(make([]byte, 4*KB), bufio.MaxScanTokenSize)
 
 		for scanner.Scan() {
 			// update latest time
@@ -227,6 +320,7 @@ func (e *ExternalProgramExecutor) OnProgress(splitFunction func([]byte, bool) (i
 	return progressOutput
 }
 
+// OnMessages 읽지 않으면 외부 프로그램이 block 되므로, 여러 구독자가 필요하면 Execution.Stream 사용
 func (e *ExternalProgramExecutor) OnMessages(splitFunction func([]byte, bool) (int, []byte, error),
 	scanFunction func(*bufio.Scanner, chan string, interface{}), pipe io.ReadCloser, args interface{}) <-chan string {
 
@@ -247,7 +341,7 @@ func (e *ExternalProgramExecutor) OnMessages(splitFunction func([]byte, bool) (i
 
 		scanner := bufio.NewScanner(pipe)
 		scanner.Split(splitFunction)
-		scanner.Buffer(make([]byte, 2), bufio.MaxScanTokenSize)
+		scanner.Buffer(make([]byte, 4*KB), bufio.MaxScanTokenSize)
 
 		for scanner.Scan() {
 			scanFunction(scanner, messageOutput, args)
@@ -269,17 +363,10 @@ func (e *ExternalProgramArguments) getArguments() []string {
 	return args
 }
 
-func findExecPath(execName string) (string, error) {
-
-	// exec cmd
-	command := exec.Command(GetWhereIs(), execName)
-
-	var output bytes.Buffer
-	command.Stdout = &output
-
-	if err := command.Run(); err != nil {
-		return "", err
+// findExecPath ExecPath 가 있으면 ExecPath, 없으면 execName 을 검색
+func (e *ExternalProgramExecutor) findExecPath() (string, error) {
+	if e.ExecPath != "" {
+		return LookupExecPath(e.ExecPath)
 	}
-
-	return strings.Trim(output.String(), "\n"), nil
+	return LookupExecPath(e.execName, e.SearchPaths...)
 }
@@ -0,0 +1,173 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func(ctx context.Context) (context.Context, context.CancelFunc)
+		want   *NeptuneError
+	}{
+		{
+			name: "deadline",
+			cancel: func(ctx context.Context) (context.Context, context.CancelFunc) {
+				return context.WithTimeout(ctx, 200*time.Millisecond)
+			},
+			want: ErrExecTimeout,
+		},
+		{
+			name: "cancel",
+			cancel: func(ctx context.Context) (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(ctx)
+				time.AfterFunc(200*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			want: ErrSigTerm,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := test.cancel(context.Background())
+			defer cancel()
+
+			// Run 은 stdout 을 끝까지 읽으므로, stdout 을 물려받은 grandchild 까지 종료되어야 반환된다
+			type runResult struct {
+				result *ExecutionResult
+				err    error
+			}
+			done := make(chan runResult, 1)
+			go func() {
+				result, err := helperExecutor("spawn", "30").Run(ctx)
+				done <- runResult{result, err}
+			}()
+
+			select {
+			case run := <-done:
+				if !errors.Is(run.err, test.want) {
+					t.Errorf("Run() error = %v, want code %v", run.err, test.want.Code())
+				}
+				if run.result == nil || run.result.Signal == "" {
+					t.Errorf("Run() result = %+v, want terminated by signal", run.result)
+				}
+				if ExecutionResultOf(run.err) != run.result {
+					t.Errorf("ExecutionResultOf() does not return the result of Run")
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("Run() did not return after ctx is done")
+			}
+		})
+	}
+}
+
+func TestExecuteContextNotFound(t *testing.T) {
+
+	executor := NewExternalProgramExecutor("neptune-no-such-program", nil, nil)
+	output, err := executor.ExecuteContext(context.Background())
+	if output != "" || !errors.Is(err, ErrExecNotFound) {
+		t.Errorf("ExecuteContext() = %q, %v, want ErrExecNotFound", output, err)
+	}
+	if executor.GetExitCode() != -1 {
+		t.Errorf("GetExitCode() = %v, want -1", executor.GetExitCode())
+	}
+}
+
+func TestRunEnv(t *testing.T) {
+
+	t.Setenv("NEPTUNE_INHERITED", "inherited")
+
+	tests := []struct {
+		name     string
+		clearEnv bool
+		env      map[string]string
+		want     string
+	}{
+		{name: "inherit", want: "inherited,\n"},
+		{name: "override", env: map[string]string{"NEPTUNE_INHERITED": "overridden", "NEPTUNE_ADDED": "added"}, want: "overridden,added\n"},
+		{name: "clear", clearEnv: true, env: map[string]string{"NEPTUNE_ADDED": "added"}, want: ",added\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor := NewExternalProgramExecutor("/bin/sh", []string{"-c", `echo "$NEPTUNE_INHERITED,$NEPTUNE_ADDED"`}, nil)
+			executor.ClearEnv = test.clearEnv
+			for key, value := range test.env {
+				executor.SetEnv(key, value)
+			}
+			output, err := executor.ExecuteContext(context.Background())
+			if err != nil || output != test.want {
+				t.Errorf("ExecuteContext() = %q, %v, want %q", output, err, test.want)
+			}
+		})
+	}
+}
+
+func TestRunDir(t *testing.T) {
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := NewExternalProgramExecutor("sh", []string{"-c", "pwd -P"}, nil)
+	executor.Dir = dir
+	if output, err := executor.ExecuteContext(context.Background()); err != nil || output != dir+"\n" {
+		t.Errorf("ExecuteContext() = %q, %v, want %q", output, err, dir+"\n")
+	}
+
+	executor.Dir = filepath.Join(dir, "missing")
+	if _, err := executor.ExecuteContext(context.Background()); !errors.Is(err, ErrExecFailed) {
+		t.Errorf("ExecuteContext() with missing Dir error = %v, want ErrExecFailed", err)
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+
+	stdinFile := filepath.Join(t.TempDir(), "stdin.txt")
+	if err := ioutil.WriteFile(stdinFile, []byte("from file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		stdin     io.Reader
+		stdinFile string
+		want      string
+		err       *NeptuneError
+	}{
+		{name: "reader", stdin: strings.NewReader("from reader\n"), want: "from reader\n"},
+		{name: "file", stdinFile: stdinFile, want: "from file\n"},
+		{name: "reader first", stdin: strings.NewReader("from reader\n"), stdinFile: stdinFile, want: "from reader\n"},
+		{name: "missing file", stdinFile: stdinFile + ".missing", err: ErrExecFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor := NewExternalProgramExecutor("cat", nil, nil)
+			executor.Stdin, executor.StdinFile = test.stdin, test.stdinFile
+			output, err := executor.ExecuteContext(context.Background())
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Errorf("ExecuteContext() error = %v, want %v", err, test.err)
+				}
+				return
+			}
+			if err != nil || output != test.want {
+				t.Errorf("ExecuteContext() = %q, %v, want %q", output, err, test.want)
+			}
+		})
+	}
+}
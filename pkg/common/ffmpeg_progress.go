@@ -0,0 +1,209 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ffmpegProgressBufferSize 진행 정보 channel 크기. 가득 차면 새 진행 정보는 버린다 (child 가 block 되지 않도록)
+const ffmpegProgressBufferSize = 16
+
+var (
+	ffmpegStatsPattern    = regexp.MustCompile(`(\w+)=\s*(\S+)`)
+	ffmpegDurationPattern = regexp.MustCompile(`Duration:\s*(\d+:\d+:\d+(?:\.\d+)?)`)
+)
+
+// FFmpegProgress ffmpeg 진행 정보 (-progress pipe:1 출력 혹은 stderr 의 통계 출력)
+type FFmpegProgress struct {
+	Frame int64   `json:"frame"`
+	FPS   float64 `json:"fps"`
+
+	// kbits/s
+	Bitrate float64 `json:"bitrate"`
+
+	// bytes
+	TotalSize int64 `json:"total_size"`
+
+	// 처리된 출력 시간 (seconds)
+	OutTime float64 `json:"out_time"`
+
+	Speed float64 `json:"speed"`
+
+	// 0 ~ 100. 전체 길이를 모르면 0
+	Percent float64 `json:"percent"`
+
+	// progress=end
+	Finished bool `json:"finished"`
+}
+
+// FFmpegProgressParser ffmpeg 출력을 한 줄씩 받아 FFmpegProgress 로 변환
+type FFmpegProgressParser struct {
+	// 입력 전체 길이 (seconds). 0 이면 stderr 의 "Duration:" 로 추정
+	TotalDuration float64
+
+	current FFmpegProgress
+}
+
+func NewFFmpegProgressParser(totalDuration float64) *FFmpegProgressParser {
+	return &FFmpegProgressParser{TotalDuration: totalDuration}
+}
+
+// Parse 한 줄을 처리하고 진행 정보가 완성되면 반환 (그 외에는 nil)
+func (p *FFmpegProgressParser) Parse(line string) *FFmpegProgress {
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+
+	// header : Duration: 00:01:00.00, start: ...
+	if p.TotalDuration <= 0 {
+		if match := ffmpegDurationPattern.FindStringSubmatch(line); match != nil {
+			p.TotalDuration = DurationToSecond(match[1])
+			return nil
+		}
+	}
+
+	// stderr 통계 : frame=  120 fps= 30 q=28.0 size=  256kB time=00:00:04.00 bitrate= 524.3kbits/s speed=1.99x
+	if strings.HasPrefix(line, "frame=") || strings.HasPrefix(line, "size=") {
+		if strings.Contains(line, " ") || strings.Contains(line, "time=") {
+			for _, match := range ffmpegStatsPattern.FindAllStringSubmatch(line, -1) {
+				p.set(match[1], match[2])
+			}
+			return p.emit(false)
+		}
+	}
+
+	// -progress 출력 : key=value 형식, progress=continue|end 로 한 블록이 끝남
+	index := strings.Index(line, "=")
+	if index <= 0 {
+		return nil
+	}
+	key, value := line[:index], strings.TrimSpace(line[index+1:])
+	if key == "progress" {
+		return p.emit(value == "end")
+	}
+	p.set(key, value)
+	return nil
+}
+
+func (p *FFmpegProgressParser) set(key string, value string) {
+	switch key {
+	case "frame":
+		p.current.Frame, _ = strconv.ParseInt(value, 10, 64)
+	case "fps":
+		p.current.FPS, _ = strconv.ParseFloat(value, 64)
+	case "bitrate":
+		p.current.Bitrate, _ = strconv.ParseFloat(strings.TrimSuffix(value, "kbits/s"), 64)
+	case "total_size":
+		p.current.TotalSize, _ = strconv.ParseInt(value, 10, 64)
+	case "size", "Lsize":
+		// stderr : 256kB
+		if size, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSuffix(value, "kB"), "KiB"), 10, 64); err == nil {
+			p.current.TotalSize = size * KB
+		}
+	case "out_time_us", "out_time_ms":
+		// out_time_ms 도 microseconds 단위
+		if micro, err := strconv.ParseInt(value, 10, 64); err == nil {
+			p.current.OutTime = float64(micro) / float64(time.Second/time.Microsecond)
+		}
+	case "out_time", "time":
+		if seconds := DurationToSecond(value); seconds > 0 {
+			p.current.OutTime = seconds
+		}
+	case "speed":
+		p.current.Speed, _ = strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+	}
+}
+
+func (p *FFmpegProgressParser) emit(finished bool) *FFmpegProgress {
+	progress := p.current
+	progress.Finished = finished
+	switch {
+	case finished:
+		progress.Percent = 100
+	case p.TotalDuration > 0:
+		progress.Percent = progress.OutTime * 100 / p.TotalDuration
+		if progress.Percent > 100 {
+			progress.Percent = 100
+		}
+	}
+	return &progress
+}
+
+// ScanFFmpegLines '\n' 혹은 '\r' 로 구분되는 줄 단위 split 함수 (ffmpeg 통계 출력은 '\r' 로 갱신됨)
+func ScanFFmpegLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if index := bytes.IndexAny(data, "\r\n"); index >= 0 {
+		return index + 1, data[:index], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// OnFFmpegProgress pipe (-progress pipe:1 이면 stdout, 아니면 stderr) 를 읽어 진행 정보를 전달하고 hang 감시 시간을 갱신.
+// totalDuration (seconds) 이 0 이면 ffmpeg 출력의 Duration 으로 추정한다.
+// 전송은 block 되지 않으므로 읽지 않아도 ffmpeg 는 멈추지 않는다. 읽는 쪽이 느리면 중간 진행 정보는 버려지고,
+// 마지막 (Finished) 진행 정보는 가장 오래된 진행 정보를 버려서라도 channel 에 넣는다. pipe 가 끝나면 channel 은 닫힌다
+func (e *ExternalProgramExecutor) OnFFmpegProgress(pipe io.ReadCloser, totalDuration float64) <-chan *FFmpegProgress {
+
+	progressOutput := make(chan *FFmpegProgress, ffmpegProgressBufferSize)
+
+	go func() {
+		defer close(progressOutput)
+
+		if pipe == nil {
+			return
+		}
+		defer func(pipe io.ReadCloser) {
+			_ = pipe.Close()
+		}(pipe)
+
+		parser := NewFFmpegProgressParser(totalDuration)
+		scanner := bufio.NewScanner(pipe)
+		scanner.Split(ScanFFmpegLines)
+		scanner.Buffer(make([]byte, 4*KB), bufio.MaxScanTokenSize)
+
+		for scanner.Scan() {
+			// update latest time
+			atomic.StoreInt64(&e.latestTime, time.Now().Unix())
+
+			progress := parser.Parse(scanner.Text())
+			if progress == nil {
+				continue
+			}
+			offerProgress(progressOutput, progress)
+		}
+	}()
+
+	return progressOutput
+}
+
+// offerProgress block 되지 않고 전송. channel 이 가득 찬 경우 중간 진행 정보는 버리고,
+// 마지막 진행 정보는 가장 오래된 것을 꺼내고 넣는다 (전송하는 goroutine 이 하나이므로 두번째 시도는 성공한다)
+func offerProgress(progressOutput chan *FFmpegProgress, progress *FFmpegProgress) {
+	for {
+		select {
+		case progressOutput <- progress:
+			return
+		default:
+		}
+		if !progress.Finished {
+			return
+		}
+		select {
+		case <-progressOutput:
+		default:
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFFmpegProgressParser(t *testing.T) {
+	tests := []struct {
+		name          string
+		totalDuration float64
+		lines         []string
+		want          []FFmpegProgress
+	}{
+		{
+			name:          "progress pipe",
+			totalDuration: 8,
+			lines: []string{
+				"frame=120", "fps=30.00", "bitrate= 524.3kbits/s", "total_size=262144",
+				"out_time_us=4000000", "out_time=00:00:04.000000", "speed=1.99x", "progress=continue",
+				"frame=240", "out_time_us=8000000", "progress=end",
+			},
+			want: []FFmpegProgress{
+				{Frame: 120, FPS: 30, Bitrate: 524.3, TotalSize: 262144, OutTime: 4, Speed: 1.99, Percent: 50},
+				{Frame: 240, FPS: 30, Bitrate: 524.3, TotalSize: 262144, OutTime: 8, Speed: 1.99, Percent: 100, Finished: true},
+			},
+		},
+		{
+			name: "stderr stats with duration header",
+			lines: []string{
+				"Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'input.mp4':",
+				"  Duration: 00:00:10.00, start: 0.000000, bitrate: 1205 kb/s",
+				"frame=  120 fps= 30 q=28.0 size=     256kB time=00:00:05.00 bitrate= 524.3kbits/s speed=1.99x",
+			},
+			want: []FFmpegProgress{
+				{Frame: 120, FPS: 30, Bitrate: 524.3, TotalSize: 256 * KB, OutTime: 5, Speed: 1.99, Percent: 50},
+			},
+		},
+		{
+			name:  "unknown duration",
+			lines: []string{"out_time_ms=3000000", "progress=continue"},
+			want:  []FFmpegProgress{{OutTime: 3}},
+		},
+		{
+			name:  "no progress",
+			lines: []string{"", "ffmpeg version 4.4", "Press [q] to stop, [?] for help"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := NewFFmpegProgressParser(test.totalDuration)
+			var got []FFmpegProgress
+			for _, line := range test.lines {
+				if progress := parser.Parse(line); progress != nil {
+					got = append(got, *progress)
+				}
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("Parse() returned %v progress, want %v : %+v", len(got), len(test.want), got)
+			}
+			for index := range got {
+				// Percent 는 부동소수점 계산
+				if math.Abs(got[index].Percent-test.want[index].Percent) > 1e-9 {
+					t.Errorf("[%v] Percent = %v, want %v", index, got[index].Percent, test.want[index].Percent)
+				}
+				got[index].Percent = test.want[index].Percent
+				if !reflect.DeepEqual(got[index], test.want[index]) {
+					t.Errorf("[%v] Parse() = %+v, want %+v", index, got[index], test.want[index])
+				}
+			}
+		})
+	}
+}
+
+func TestScanFFmpegLines(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "a\nb\n", want: []string{"a", "b"}},
+		{input: "frame=1\rframe=2\rframe=3", want: []string{"frame=1", "frame=2", "frame=3"}},
+		{input: "a\r\nb", want: []string{"a", "", "b"}},
+		{input: "", want: nil},
+	}
+
+	for _, test := range tests {
+		scanner := bufio.NewScanner(strings.NewReader(test.input))
+		scanner.Split(ScanFFmpegLines)
+		var got []string
+		for scanner.Scan() {
+			got = append(got, scanner.Text())
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ScanFFmpegLines(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestOnFFmpegProgress(t *testing.T) {
+
+	pipe := ioutil.NopCloser(strings.NewReader("frame=10\rout_time_us=1000000\nprogress=continue\nframe=20\nprogress=end\n"))
+	executor := NewExternalProgramExecutor("ffmpeg", nil, nil)
+
+	var got []*FFmpegProgress
+	for progress := range executor.OnFFmpegProgress(pipe, 2) {
+		got = append(got, progress)
+	}
+	if len(got) != 2 || got[0].Frame != 10 || got[0].Percent != 50 || !got[1].Finished || got[1].Frame != 20 {
+		t.Errorf("OnFFmpegProgress() = %+v", got)
+	}
+
+	// pipe 가 없으면 바로 닫힌다
+	if _, ok := <-executor.OnFFmpegProgress(nil, 0); ok {
+		t.Error("OnFFmpegProgress(nil) is not closed")
+	}
+}
+
+// closeNotifier Close 가 호출되면 closed 를 닫는 pipe
+type closeNotifier struct {
+	*strings.Reader
+	closed chan struct{}
+}
+
+func (c *closeNotifier) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestOnFFmpegProgressSlowReader(t *testing.T) {
+
+	var output strings.Builder
+	for frame := 1; frame <= 10*ffmpegProgressBufferSize; frame++ {
+		fmt.Fprintf(&output, "frame=%v\nprogress=continue\n", frame)
+	}
+	output.WriteString("frame=999\nprogress=end\n")
+
+	// 읽지 않아도 pipe 를 끝까지 읽는다 (pipe 는 마지막 전송 후에 닫힌다)
+	pipe := &closeNotifier{Reader: strings.NewReader(output.String()), closed: make(chan struct{})}
+	executor := NewExternalProgramExecutor("ffmpeg", nil, nil)
+	progressOutput := executor.OnFFmpegProgress(pipe, 0)
+	select {
+	case <-pipe.closed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("OnFFmpegProgress blocked on a slow reader")
+	}
+
+	var last *FFmpegProgress
+	count := 0
+	for progress := range progressOutput {
+		last = progress
+		count++
+	}
+	if count != ffmpegProgressBufferSize {
+		t.Errorf("received %v progress, want %v", count, ffmpegProgressBufferSize)
+	}
+	// 마지막 진행 정보는 버려지지 않는다
+	if last == nil || !last.Finished || last.Frame != 999 {
+		t.Errorf("last progress = %+v, want finished frame 999", last)
+	}
+}
@@ -0,0 +1,153 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	HealthStatusOk   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthCheckFunc 상태 점검 함수 (nil 반환시 정상)
+type HealthCheckFunc func(ctx context.Context) error
+
+type HealthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func (h *HealthReport) Healthy() bool {
+	return h.Status == HealthStatusOk
+}
+
+// HealthRegistry WSServer, GrpcServer 가 공유하는 상태 점검 목록
+type HealthRegistry struct {
+	mutex     sync.RWMutex
+	liveness  map[string]HealthCheckFunc
+	readiness map[string]HealthCheckFunc
+	ready     int32
+}
+
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{
+		liveness:  map[string]HealthCheckFunc{},
+		readiness: map[string]HealthCheckFunc{},
+		ready:     1,
+	}
+}
+
+// AddLivenessCheck /healthz 에서 점검할 항목 추가
+func (r *HealthRegistry) AddLivenessCheck(name string, check HealthCheckFunc) *HealthRegistry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.liveness[name] = check
+	return r
+}
+
+// AddReadinessCheck /readyz, grpc health service 에서 점검할 항목 추가
+func (r *HealthRegistry) AddReadinessCheck(name string, check HealthCheckFunc) *HealthRegistry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.readiness[name] = check
+	return r
+}
+
+func (r *HealthRegistry) RemoveCheck(name string) *HealthRegistry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.liveness, name)
+	delete(r.readiness, name)
+	return r
+}
+
+// SetReady shutdown 시작시 false 로 설정하여 readiness 를 내린다
+func (r *HealthRegistry) SetReady(ready bool) {
+	if ready {
+		atomic.StoreInt32(&r.ready, 1)
+	} else {
+		atomic.StoreInt32(&r.ready, 0)
+	}
+}
+
+func (r *HealthRegistry) IsReady() bool {
+	return atomic.LoadInt32(&r.ready) == 1
+}
+
+func (r *HealthRegistry) CheckLiveness(ctx context.Context) *HealthReport {
+	return runHealthChecks(ctx, r.snapshot(r.liveness))
+}
+
+func (r *HealthRegistry) CheckReadiness(ctx context.Context) *HealthReport {
+	report := runHealthChecks(ctx, r.snapshot(r.readiness))
+	if !r.IsReady() {
+		report.Status = HealthStatusFail
+		report.Checks["shutdown"] = "server is shutting down"
+	}
+	return report
+}
+
+// Check 이름으로 등록된 단일 항목 점검
+func (r *HealthRegistry) Check(ctx context.Context, name string) (*HealthReport, bool) {
+	r.mutex.RLock()
+	check, ok := r.readiness[name]
+	if !ok {
+		check, ok = r.liveness[name]
+	}
+	r.mutex.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return runHealthChecks(ctx, map[string]HealthCheckFunc{name: check}), true
+}
+
+func (r *HealthRegistry) snapshot(checks map[string]HealthCheckFunc) map[string]HealthCheckFunc {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	copied := make(map[string]HealthCheckFunc, len(checks))
+	for name, check := range checks {
+		copied[name] = check
+	}
+	return copied
+}
+
+func runHealthChecks(ctx context.Context, checks map[string]HealthCheckFunc) *HealthReport {
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := &HealthReport{
+		Status: HealthStatusOk,
+		Checks: map[string]string{},
+	}
+	for _, name := range names {
+		if err := checks[name](ctx); err != nil {
+			report.Status = HealthStatusFail
+			report.Checks[name] = err.Error()
+		} else {
+			report.Checks[name] = HealthStatusOk
+		}
+	}
+	return report
+}
+
+// DiskSpaceCheck path 의 여유 공간이 minFree (bytes) 미만이면 실패
+func DiskSpaceCheck(path string, minFree uint64) HealthCheckFunc {
+	return func(ctx context.Context) error {
+		disk, err := DiskUsage(path)
+		if err != nil {
+			return err
+		}
+		if disk.Free < minFree {
+			return fmt.Errorf("low disk space (path:%v, free:%v, required:%v)", path, disk.Free, minFree)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,276 @@
+package common
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTailLines OutputStream 이 메모리에 보관하는 마지막 줄 수 기본값
+const DefaultTailLines = 1000
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// OutputLine 외부 프로그램 출력 한 줄
+type OutputLine struct {
+	Stream string    `json:"stream"`
+	Time   time.Time `json:"time"`
+	Text   string    `json:"text"`
+}
+
+// OutputStreamConfig Execution.Stream 설정
+type OutputStreamConfig struct {
+	// stream 별로 메모리에 보관할 마지막 줄 수 (0 이면 DefaultTailLines)
+	TailLines int
+
+	// 줄 구분 함수 (nil 이면 bufio.ScanLines, ffmpeg 통계 출력은 ScanFFmpegLines)
+	Split bufio.SplitFunc
+
+	// 가공하지 않은 출력을 그대로 기록할 writer (RotatingFile 등). 같은 writer 를 함께 사용할 수 있다
+	StdoutTee io.Writer
+	StderrTee io.Writer
+}
+
+// OutputStream stdout, stderr 를 동시에 읽어 구독자에게 줄 단위로 전달. 구독자가 느려도 외부 프로그램은 block 되지 않으며,
+// 구독자의 buffer 가 가득 차면 그 구독자에게는 해당 줄이 버려진다
+type OutputStream struct {
+	mutex       sync.Mutex
+	subscribers map[chan *OutputLine]struct{}
+	finished    bool
+
+	// stream 별 마지막 줄
+	tails map[string]*lineRing
+
+	dropped int64
+	done    chan struct{}
+}
+
+// Stream Start 로 시작한 실행의 stdout, stderr 를 OutputStream 으로 처리. pipe 는 OutputStream 이 읽고 닫으므로
+// StdOutPipe, StdErrPipe 를 따로 읽으면 안 된다. 출력이 있을 때마다 HangTimeout 을 갱신한다
+func (x *Execution) Stream(config *OutputStreamConfig) *OutputStream {
+	if config == nil {
+		config = &OutputStreamConfig{}
+	}
+	return newOutputStream(config, func() {
+		atomic.StoreInt64(&x.executor.latestTime, time.Now().Unix())
+	}, map[string]io.ReadCloser{
+		StreamStdout: x.stdOutPipe,
+		StreamStderr: x.stdErrPipe,
+	}, map[string]io.Writer{
+		StreamStdout: config.StdoutTee,
+		StreamStderr: config.StderrTee,
+	})
+}
+
+func newOutputStream(config *OutputStreamConfig, onActivity func(),
+	pipes map[string]io.ReadCloser, tees map[string]io.Writer) *OutputStream {
+
+	tailLines := config.TailLines
+	if tailLines <= 0 {
+		tailLines = DefaultTailLines
+	}
+	split := config.Split
+	if split == nil {
+		split = bufio.ScanLines
+	}
+
+	outputStream := &OutputStream{
+		subscribers: map[chan *OutputLine]struct{}{},
+		tails:       map[string]*lineRing{},
+		done:        make(chan struct{}),
+	}
+	for stream := range pipes {
+		outputStream.tails[stream] = &lineRing{lines: make([]*OutputLine, tailLines)}
+	}
+
+	var waitGroup sync.WaitGroup
+	for stream, pipe := range pipes {
+		if pipe == nil {
+			continue
+		}
+		waitGroup.Add(1)
+		go func(stream string, pipe io.ReadCloser, tee io.Writer) {
+			defer waitGroup.Done()
+			outputStream.read(stream, pipe, tee, split, onActivity)
+		}(stream, pipe, tees[stream])
+	}
+
+	go func() {
+		waitGroup.Wait()
+		outputStream.finish()
+	}()
+
+	return outputStream
+}
+
+func (o *OutputStream) read(stream string, pipe io.ReadCloser, tee io.Writer, split bufio.SplitFunc, onActivity func()) {
+
+	defer func(pipe io.ReadCloser) {
+		_ = pipe.Close()
+	}(pipe)
+
+	var reader io.Reader = pipe
+	if tee != nil {
+		reader = io.TeeReader(pipe, &ignoreErrorWriter{writer: tee})
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(split)
+	scanner.Buffer(make([]byte, 4*KB), bufio.MaxScanTokenSize)
+
+	for scanner.Scan() {
+		onActivity()
+		o.publish(&OutputLine{
+			Stream: stream,
+			Time:   time.Now(),
+			Text:   scanner.Text(),
+		})
+	}
+
+	// 너무 긴 줄 (bufio.ErrTooLong) 등으로 중단되어도 외부 프로그램이 SIGPIPE 를 받지 않도록 끝까지 읽어서 버린다 (tee 에는 기록)
+	if err := scanner.Err(); err != nil {
+		logger.Warn("output stream scan error. discard the rest of output", "stream", stream, "err", err)
+		if _, err := io.Copy(io.Discard, reader); err != nil {
+			logger.Warn("output stream discard error", "stream", stream, "err", err)
+		}
+	}
+}
+
+func (o *OutputStream) publish(line *OutputLine) {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.tails[line.Stream].add(line)
+
+	for subscriber := range o.subscribers {
+		select {
+		case subscriber <- line:
+		default:
+			atomic.AddInt64(&o.dropped, 1)
+		}
+	}
+}
+
+func (o *OutputStream) finish() {
+	o.mutex.Lock()
+	o.finished = true
+	for subscriber := range o.subscribers {
+		close(subscriber)
+		delete(o.subscribers, subscriber)
+	}
+	o.mutex.Unlock()
+	close(o.done)
+}
+
+// Subscribe 이후의 출력을 받을 channel (buffer 크기 size) 과 구독 해지 함수 반환. 출력이 끝나면 channel 은 닫힌다
+func (o *OutputStream) Subscribe(size int) (<-chan *OutputLine, func()) {
+
+	subscriber := make(chan *OutputLine, size)
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.finished {
+		close(subscriber)
+		return subscriber, func() {}
+	}
+	o.subscribers[subscriber] = struct{}{}
+
+	var once sync.Once
+	return subscriber, func() {
+		once.Do(func() {
+			o.mutex.Lock()
+			defer o.mutex.Unlock()
+			if _, ok := o.subscribers[subscriber]; ok {
+				delete(o.subscribers, subscriber)
+				close(subscriber)
+			}
+		})
+	}
+}
+
+// Tail stream (StreamStdout, StreamStderr) 별로 보관중인 마지막 줄들 (오래된 순서). stream 이 빈 문자열이면 모든 stream 을 시간 순서로
+func (o *OutputStream) Tail(stream string) []*OutputLine {
+
+	o.mutex.Lock()
+	var lines []*OutputLine
+	for name, tail := range o.tails {
+		if stream == "" || stream == name {
+			lines = append(lines, tail.list()...)
+		}
+	}
+	o.mutex.Unlock()
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time.Before(lines[j].Time)
+	})
+	return lines
+}
+
+// TailString Tail 을 에러 보고용 문자열로 반환
+func (o *OutputStream) TailString(stream string) string {
+	var builder strings.Builder
+	for _, line := range o.Tail(stream) {
+		builder.WriteString(line.Text)
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+// Dropped buffer 가 가득 차서 구독자에게 전달하지 못한 줄 수
+func (o *OutputStream) Dropped() int64 {
+	return atomic.LoadInt64(&o.dropped)
+}
+
+// Done stdout, stderr 를 모두 읽으면 닫힌다
+func (o *OutputStream) Done() <-chan struct{} {
+	return o.done
+}
+
+// lineRing 마지막 len(lines) 줄을 보관하는 ring buffer
+type lineRing struct {
+	lines []*OutputLine
+	start int
+	count int
+}
+
+func (r *lineRing) add(line *OutputLine) {
+	r.lines[(r.start+r.count)%len(r.lines)] = line
+	if r.count < len(r.lines) {
+		r.count++
+	} else {
+		r.start = (r.start + 1) % len(r.lines)
+	}
+}
+
+func (r *lineRing) list() []*OutputLine {
+	lines := make([]*OutputLine, 0, r.count)
+	for index := 0; index < r.count; index++ {
+		lines = append(lines, r.lines[(r.start+index)%len(r.lines)])
+	}
+	return lines
+}
+
+// ignoreErrorWriter tee 대상의 쓰기 에러 (디스크 부족 등) 로 출력 읽기가 중단되지 않도록 에러를 무시
+type ignoreErrorWriter struct {
+	writer io.Writer
+	failed int32
+}
+
+func (w *ignoreErrorWriter) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&w.failed) == 0 {
+		if _, err := w.writer.Write(p); err != nil {
+			logger.Warn("output tee write error. stop writing", "err", err)
+			atomic.StoreInt32(&w.failed, 1)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,212 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestOutputStream stdout 만 있는 OutputStream 과 출력을 쓸 writer
+func newTestOutputStream(config *OutputStreamConfig) (*OutputStream, *io.PipeWriter) {
+	reader, writer := io.Pipe()
+	return newOutputStream(config, func() {}, map[string]io.ReadCloser{
+		StreamStdout: reader,
+	}, map[string]io.Writer{
+		StreamStdout: config.StdoutTee,
+	}), writer
+}
+
+func waitOutputStream(t *testing.T, outputStream *OutputStream) {
+	t.Helper()
+	select {
+	case <-outputStream.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("output stream did not finish")
+	}
+}
+
+func readLines(subscriber <-chan *OutputLine) []string {
+	var lines []string
+	for line := range subscriber {
+		lines = append(lines, line.Text)
+	}
+	return lines
+}
+
+func TestOutputStreamSubscribers(t *testing.T) {
+
+	var tee bytes.Buffer
+	outputStream, writer := newTestOutputStream(&OutputStreamConfig{TailLines: 3, StdoutTee: &tee})
+
+	fast, _ := outputStream.Subscribe(100)
+	slow, _ := outputStream.Subscribe(1)
+	unsubscribed, unsubscribe := outputStream.Subscribe(100)
+	unsubscribe()
+	unsubscribe()
+
+	var want []string
+	for index := 0; index < 10; index++ {
+		want = append(want, fmt.Sprintf("line %v", index))
+		if _, err := fmt.Fprintln(writer, want[index]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = writer.Close()
+	waitOutputStream(t, outputStream)
+
+	// 읽지 않는 구독자가 있어도 block 되지 않고, 그 구독자의 줄만 버려진다
+	if got := readLines(fast); !reflect.DeepEqual(got, want) {
+		t.Errorf("fast subscriber = %v, want %v", got, want)
+	}
+	if got := readLines(slow); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("slow subscriber = %v, want %v", got, want[:1])
+	}
+	if got := readLines(unsubscribed); len(got) != 0 {
+		t.Errorf("unsubscribed = %v, want nothing", got)
+	}
+	if outputStream.Dropped() != 9 {
+		t.Errorf("Dropped() = %v, want 9", outputStream.Dropped())
+	}
+
+	if tail := outputStream.TailString(StreamStdout); tail != "line 7\nline 8\nline 9\n" {
+		t.Errorf("TailString() = %q", tail)
+	}
+	if tee.String() != strings.Join(want, "\n")+"\n" {
+		t.Errorf("tee = %q", tee.String())
+	}
+
+	// 끝난 뒤의 구독은 바로 닫힌다
+	if _, ok := <-mustSubscribe(outputStream); ok {
+		t.Error("Subscribe() after finish is not closed")
+	}
+}
+
+func mustSubscribe(outputStream *OutputStream) <-chan *OutputLine {
+	subscriber, _ := outputStream.Subscribe(1)
+	return subscriber
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestOutputStreamTeeError(t *testing.T) {
+
+	tee := &failingWriter{}
+	outputStream, writer := newTestOutputStream(&OutputStreamConfig{StdoutTee: tee})
+	subscriber, _ := outputStream.Subscribe(10)
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		_, _ = io.WriteString(writer, line)
+	}
+	_ = writer.Close()
+	waitOutputStream(t, outputStream)
+
+	// tee 에러는 첫번째 이후로 쓰지 않고, 출력은 계속 읽는다
+	if got := readLines(subscriber); !reflect.DeepEqual(got, []string{"one", "two", "three"}) {
+		t.Errorf("subscriber = %v", got)
+	}
+	if tee.writes != 1 {
+		t.Errorf("tee writes = %v, want 1", tee.writes)
+	}
+}
+
+func TestOutputStreamLongLine(t *testing.T) {
+
+	var tee bytes.Buffer
+	outputStream, writer := newTestOutputStream(&OutputStreamConfig{StdoutTee: &tee})
+	subscriber, _ := outputStream.Subscribe(10)
+
+	// bufio.MaxScanTokenSize 보다 긴 줄 이후의 출력도 pipe 를 닫지 않고 읽어야 한다
+	output := "before\n" + strings.Repeat("x", 2*bufio.MaxScanTokenSize) + "\nafter\n"
+	written := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(writer, output)
+		_ = writer.Close()
+		written <- err
+	}()
+	waitOutputStream(t, outputStream)
+
+	if err := <-written; err != nil {
+		t.Errorf("write error = %v, want nil", err)
+	}
+	if got := readLines(subscriber); !reflect.DeepEqual(got, []string{"before"}) {
+		t.Errorf("subscriber = %v, want [before]", got)
+	}
+	if tee.String() != output {
+		t.Errorf("tee length = %v, want %v", tee.Len(), len(output))
+	}
+}
+
+func TestLineRing(t *testing.T) {
+	tests := []struct {
+		size  int
+		lines []string
+		want  []string
+	}{
+		{size: 3, lines: []string{"a", "b"}, want: []string{"a", "b"}},
+		{size: 3, lines: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}},
+		{size: 3, lines: []string{"a", "b", "c", "d", "e"}, want: []string{"c", "d", "e"}},
+		{size: 1, lines: []string{"a", "b"}, want: []string{"b"}},
+	}
+
+	for _, test := range tests {
+		ring := &lineRing{lines: make([]*OutputLine, test.size)}
+		for _, text := range test.lines {
+			ring.add(&OutputLine{Text: text})
+		}
+		var got []string
+		for _, line := range ring.list() {
+			got = append(got, line.Text)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("lineRing(%v) %v = %v, want %v", test.size, test.lines, got, test.want)
+		}
+	}
+}
+
+func TestExecutionStream(t *testing.T) {
+
+	executor := NewExternalProgramExecutor("sh", []string{"-c", "echo out; echo err >&2; sleep 0.3; echo done"}, nil)
+	execution, err := executor.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputStream := execution.Stream(nil)
+	subscriber, _ := outputStream.Subscribe(10)
+
+	if _, err := waitExecution(t, execution); err != nil {
+		t.Fatal(err)
+	}
+	waitOutputStream(t, outputStream)
+
+	streams := map[string][]string{}
+	for line := range subscriber {
+		streams[line.Stream] = append(streams[line.Stream], line.Text)
+	}
+	// 구독 전에 출력된 줄은 받지 못할 수 있으므로 Tail 로 확인
+	if got := outputStream.TailString(StreamStdout); got != "out\ndone\n" {
+		t.Errorf("stdout tail = %q", got)
+	}
+	if got := outputStream.TailString(StreamStderr); got != "err\n" {
+		t.Errorf("stderr tail = %q", got)
+	}
+	if got := streams[StreamStdout]; len(got) == 0 || got[len(got)-1] != "done" {
+		t.Errorf("stdout subscriber = %v, want ending with done", got)
+	}
+}
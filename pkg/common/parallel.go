@@ -0,0 +1,138 @@
+package common
+
+import (
+	"context"
+	"sync"
+)
+
+// Group 최대 limit 개의 goroutine 을 동시에 실행. 처음 에러가 나면 Group 의 context 를 취소한다
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	waitGroup sync.WaitGroup
+	slots     chan struct{}
+
+	once sync.Once
+	err  error
+}
+
+// NewGroup limit 이 0 이하면 제한 없음. 반환한 context 는 처음 에러가 나거나 Wait 가 끝나면 취소된다
+func NewGroup(ctx context.Context, limit int) (*Group, context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	group := &Group{}
+	group.ctx, group.cancel = context.WithCancel(ctx)
+	if limit > 0 {
+		group.slots = make(chan struct{}, limit)
+	}
+	return group, group.ctx
+}
+
+// Go 빈 자리가 생길 때까지 기다린 후 fn 실행. 기다리는 동안 context 가 취소되면 fn 을 실행하지 않고 context 에러를 기록한다
+func (g *Group) Go(fn func(ctx context.Context) error) {
+
+	if g.slots != nil {
+		select {
+		case g.slots <- struct{}{}:
+		case <-g.ctx.Done():
+			g.setErr(g.ctx.Err())
+			return
+		}
+		// 빈 자리와 취소가 동시에 생긴 경우
+		if err := g.ctx.Err(); err != nil {
+			<-g.slots
+			g.setErr(err)
+			return
+		}
+	} else if err := g.ctx.Err(); err != nil {
+		g.setErr(err)
+		return
+	}
+
+	g.waitGroup.Add(1)
+	go func() {
+		defer func() {
+			if g.slots != nil {
+				<-g.slots
+			}
+			g.waitGroup.Done()
+		}()
+		if err := fn(g.ctx); err != nil {
+			g.setErr(err)
+		}
+	}()
+}
+
+func (g *Group) setErr(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel()
+	})
+}
+
+// Wait 실행한 모든 goroutine 이 끝날 때까지 기다린 후 처음 발생한 에러 반환
+func (g *Group) Wait() error {
+	g.waitGroup.Wait()
+	g.cancel()
+	return g.err
+}
+
+// ParallelMap items 를 최대 concurrency 개씩 동시에 fn 으로 변환. 결과는 items 와 같은 순서이며,
+// 처음 에러가 나면 나머지 fn 의 ctx 를 취소하고 모두 끝난 뒤 그 에러를 반환
+func ParallelMap[T any, R any](ctx context.Context, items []T, concurrency int,
+	fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+
+	results := make([]R, len(items))
+	group, _ := NewGroup(ctx, concurrency)
+	for index, item := range items {
+		index, item := index, item
+		group.Go(func(ctx context.Context) error {
+			result, err := fn(ctx, item)
+			if err != nil {
+				return err
+			}
+			results[index] = result
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Batches items 를 size 개씩 나눈다 (마지막 batch 는 더 작을 수 있음). batch 는 items 를 공유한다
+func Batches[T any](items []T, size int) [][]T {
+	if size <= 0 {
+		size = len(items)
+	}
+	var batches [][]T
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		batches = append(batches, items[start:end:end])
+	}
+	return batches
+}
+
+// ForEachBatch items 를 size 개씩 나눠 최대 concurrency 개 batch 를 동시에 fn 으로 처리 (0 이하면 1, 순서대로 처리).
+// 처음 에러가 나면 나머지 batch 는 실행하지 않고 그 에러를 반환
+func ForEachBatch[T any](ctx context.Context, items []T, size int, concurrency int,
+	fn func(ctx context.Context, batch []T) error) error {
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	group, _ := NewGroup(ctx, concurrency)
+	for _, batch := range Batches(items, size) {
+		batch := batch
+		group.Go(func(ctx context.Context) error {
+			return fn(ctx, batch)
+		})
+	}
+	return group.Wait()
+}
@@ -0,0 +1,244 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrencyCounter 동시에 실행중인 수와 최대값
+type concurrencyCounter struct {
+	running int32
+	max     int32
+}
+
+func (c *concurrencyCounter) enter() {
+	running := atomic.AddInt32(&c.running, 1)
+	for {
+		max := atomic.LoadInt32(&c.max)
+		if running <= max || atomic.CompareAndSwapInt32(&c.max, max, running) {
+			return
+		}
+	}
+}
+
+func (c *concurrencyCounter) leave() {
+	atomic.AddInt32(&c.running, -1)
+}
+
+func TestParallelMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       []int
+		concurrency int
+		wantMax     int32
+	}{
+		{name: "limited", items: []int{1, 2, 3, 4, 5, 6, 7, 8}, concurrency: 3, wantMax: 3},
+		{name: "sequential", items: []int{1, 2, 3}, concurrency: 1, wantMax: 1},
+		{name: "unlimited", items: []int{1, 2, 3, 4}, concurrency: 0, wantMax: 4},
+		{name: "empty", items: nil, concurrency: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var counter concurrencyCounter
+			results, err := ParallelMap(context.Background(), test.items, test.concurrency,
+				func(ctx context.Context, item int) (int, error) {
+					counter.enter()
+					defer counter.leave()
+					// 나중 item 이 먼저 끝나도 결과는 입력 순서
+					time.Sleep(time.Duration(len(test.items)-item) * 5 * time.Millisecond)
+					return item * 10, nil
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := make([]int, len(test.items))
+			for index, item := range test.items {
+				want[index] = item * 10
+			}
+			if !reflect.DeepEqual(results, want) {
+				t.Errorf("ParallelMap() = %v, want %v", results, want)
+			}
+			if counter.max > test.wantMax || len(test.items) > 0 && test.concurrency > 0 && counter.max != test.wantMax {
+				t.Errorf("max concurrency = %v, want %v", counter.max, test.wantMax)
+			}
+		})
+	}
+}
+
+func TestParallelMapError(t *testing.T) {
+
+	errFailed := errors.New("failed")
+	var canceled int32
+	results, err := ParallelMap(context.Background(), []int{0, 1, 2, 3, 4, 5, 6, 7}, 2,
+		func(ctx context.Context, item int) (string, error) {
+			if item == 1 {
+				return "", errFailed
+			}
+			select {
+			case <-ctx.Done():
+				atomic.AddInt32(&canceled, 1)
+				return "", ctx.Err()
+			case <-time.After(5 * time.Second):
+				return "late", nil
+			}
+		})
+
+	// 처음 에러를 반환하고, 실행중인 fn 은 취소되며 나머지는 실행하지 않는다
+	if results != nil || !errors.Is(err, errFailed) {
+		t.Errorf("ParallelMap() = %v, %v, want nil, %v", results, err, errFailed)
+	}
+	if canceled != 1 {
+		t.Errorf("canceled = %v, want 1", canceled)
+	}
+}
+
+func TestGroup(t *testing.T) {
+
+	errFirst := errors.New("first")
+	group, ctx := NewGroup(context.Background(), 2)
+
+	var counter concurrencyCounter
+	release := make(chan struct{})
+	for index := 0; index < 2; index++ {
+		group.Go(func(ctx context.Context) error {
+			counter.enter()
+			defer counter.leave()
+			<-release
+			return nil
+		})
+	}
+
+	// 빈 자리가 없으므로 Go 는 기다린다
+	started := make(chan struct{})
+	go func() {
+		group.Go(func(ctx context.Context) error {
+			counter.enter()
+			defer counter.leave()
+			return errFirst
+		})
+		close(started)
+	}()
+	select {
+	case <-started:
+		t.Fatal("Go() did not wait for a slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-started
+
+	if err := group.Wait(); !errors.Is(err, errFirst) {
+		t.Errorf("Wait() error = %v, want %v", err, errFirst)
+	}
+	if ctx.Err() == nil {
+		t.Error("group context is not canceled after an error")
+	}
+	if counter.max > 2 {
+		t.Errorf("max concurrency = %v, want 2", counter.max)
+	}
+
+	// 취소된 뒤의 Go 는 실행하지 않는다
+	called := false
+	group.Go(func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if err := group.Wait(); called || !errors.Is(err, errFirst) {
+		t.Errorf("Go() after cancel called = %v, Wait() = %v", called, err)
+	}
+}
+
+func TestGroupParentCancel(t *testing.T) {
+
+	parent, cancel := context.WithCancel(context.Background())
+	group, _ := NewGroup(parent, 1)
+	group.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	cancel()
+
+	// 기다리는 동안 취소되면 ctx 에러
+	group.Go(func(ctx context.Context) error {
+		t.Error("fn is called after parent cancel")
+		return nil
+	})
+	if err := group.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	tests := []struct {
+		items []int
+		size  int
+		want  [][]int
+	}{
+		{items: []int{1, 2, 3, 4, 5}, size: 2, want: [][]int{{1, 2}, {3, 4}, {5}}},
+		{items: []int{1, 2, 3, 4}, size: 2, want: [][]int{{1, 2}, {3, 4}}},
+		{items: []int{1, 2, 3}, size: 0, want: [][]int{{1, 2, 3}}},
+		{items: nil, size: 2, want: nil},
+	}
+
+	for _, test := range tests {
+		if got := Batches(test.items, test.size); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Batches(%v, %v) = %v, want %v", test.items, test.size, got, test.want)
+		}
+	}
+
+	// batch 에 append 해도 다음 batch 를 덮어쓰지 않는다
+	items := []int{1, 2, 3, 4}
+	batches := Batches(items, 2)
+	_ = append(batches[0], 99)
+	if items[2] != 3 {
+		t.Errorf("append to batch overwrote items : %v", items)
+	}
+}
+
+func TestForEachBatch(t *testing.T) {
+
+	errFailed := errors.New("failed")
+	var processed [][]int
+	err := ForEachBatch(context.Background(), []int{1, 2, 3, 4, 5, 6, 7}, 3, 0,
+		func(ctx context.Context, batch []int) error {
+			processed = append(processed, batch)
+			if batch[0] == 4 {
+				return errFailed
+			}
+			return nil
+		})
+
+	// concurrency 가 0 이하면 순서대로 처리하고 에러 이후의 batch 는 실행하지 않는다
+	if !errors.Is(err, errFailed) {
+		t.Errorf("ForEachBatch() error = %v, want %v", err, errFailed)
+	}
+	if want := [][]int{{1, 2, 3}, {4, 5, 6}}; !reflect.DeepEqual(processed, want) {
+		t.Errorf("processed = %v, want %v", processed, want)
+	}
+}
+
+func TestGoRoutineWithContextCancel(t *testing.T) {
+
+	// 끝나지 않는 callback 이 있어도 ctx 가 취소되면 바로 반환
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	results, err := GoRoutineWithContext(ctx,
+		func(ctx context.Context) interface{} {
+			return "fast"
+		},
+		func(ctx context.Context) interface{} {
+			<-release
+			return "slow"
+		})
+	if results != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("GoRoutineWithContext() = %v, %v, want nil, context.Canceled", results, err)
+	}
+}
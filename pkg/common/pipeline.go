@@ -0,0 +1,322 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// PipelineStageResult 단계별 실행 결과
+type PipelineStageResult struct {
+	Index  int              `json:"index"`
+	Name   string           `json:"name"`
+	Result *ExecutionResult `json:"result"`
+	Err    error            `json:"-"`
+}
+
+// Pipeline 여러 외부 프로그램의 stdout 을 다음 단계의 stdin 으로 연결 (decoder | filter | encoder)
+type Pipeline struct {
+	// 모든 단계의 stderr 와 마지막 단계의 stdout 에 HangTimeout (seconds) 동안 출력이 없으면 전체 종료
+	HangTimeout int64
+
+	stages []*ExternalProgramExecutor
+}
+
+func NewPipeline(stages ...*ExternalProgramExecutor) *Pipeline {
+	return &Pipeline{
+		HangTimeout: DefaultHangTimeout,
+		stages:      stages,
+	}
+}
+
+// Run 실행 후 단계별 결과 반환. 마지막 단계의 stdout 은 마지막 결과의 Stdout 에 저장된다.
+// 실패시 처음 실패한 단계의 NeptuneError 반환 (나머지 단계는 ErrSigTerm 으로 종료됨)
+func (p *Pipeline) Run(ctx context.Context) ([]*PipelineStageResult, error) {
+
+	execution, err := p.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 첫 단계의 입력이 설정되지 않았으면 EOF 를 보내서 stdin 을 기다리며 멈추지 않도록 한다
+	if pipe := execution.StdInPipe(); pipe != nil {
+		_ = pipe.Close()
+	}
+
+	var output bytes.Buffer
+	if pipe := execution.StdOutPipe(); pipe != nil {
+		_, _ = io.Copy(&output, pipe)
+		_ = pipe.Close()
+	}
+
+	results, err := execution.Wait()
+	if last := results[len(results)-1]; last.Result != nil {
+		last.Result.Stdout = output.String()
+	}
+	return results, err
+}
+
+// Start 비동기 실행. 각 단계의 stderr 는 Pipeline 이 읽어 ExecutionResult.Stderr 에 (마지막 StderrTailSize) 저장하며,
+// 첫 단계의 Stdin/StdinFile 과 마지막 단계의 Stdout 설정은 그대로 사용된다.
+// 단계 사이의 연결은 각 단계의 복사본에 설정하므로 호출자의 ExternalProgramExecutor 는 변경되지 않는다 (동시에 Start 가능)
+func (p *Pipeline) Start(ctx context.Context) (*PipelineExecution, error) {
+
+	if len(p.stages) == 0 {
+		return nil, ErrExecFailed.Copy(errors.New("empty pipeline"))
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// stage[i].Stdout -> stage[i+1].Stdin
+	stages := make([]*ExternalProgramExecutor, len(p.stages))
+	for index, stage := range p.stages {
+		copied := *stage
+		stages[index] = &copied
+	}
+
+	pipelineExecution := &PipelineExecution{
+		pipeline: p,
+		stages:   stages,
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+	pipelineExecution.touch()
+
+	var closeAfterStart []io.Closer
+	defer func() {
+		for _, closer := range closeAfterStart {
+			_ = closer.Close()
+		}
+	}()
+	for index := 0; index < len(stages)-1; index++ {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			return nil, ErrExecFailed.Copy(fmt.Errorf("pipeline pipe error : %w", err))
+		}
+		closeAfterStart = append(closeAfterStart, reader, writer)
+		stages[index].Stdout = writer
+		stages[index+1].Stdin = reader
+		stages[index+1].StdinFile = ""
+	}
+
+	for index, stage := range stages {
+		execution, err := stage.start(ctx, false, false)
+		if err != nil {
+			logger.Error("pipeline stage start error", "stage", index, "program", stage.execName, "err", err)
+			for _, started := range pipelineExecution.executions {
+				started.kill(fmt.Errorf("pipeline start failed (stage %d) : %w", index, context.Canceled))
+				<-started.Done()
+			}
+			return nil, err
+		}
+		pipelineExecution.executions = append(pipelineExecution.executions, execution)
+	}
+
+	go pipelineExecution.wait()
+
+	return pipelineExecution, nil
+}
+
+// PipelineExecution Pipeline.Start 로 시작된 실행 handle
+type PipelineExecution struct {
+	pipeline   *Pipeline
+	stages     []*ExternalProgramExecutor
+	executions []*Execution
+
+	latestTime int64
+
+	// 처음 실패한 단계
+	mutex     sync.Mutex
+	failedErr error
+
+	results []*PipelineStageResult
+	err     error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (x *PipelineExecution) touch() {
+	atomic.StoreInt64(&x.latestTime, time.Now().Unix())
+}
+
+func (x *PipelineExecution) wait() {
+
+	stages := x.stages
+	x.results = make([]*PipelineStageResult, len(x.executions))
+
+	// stderr
+	stderrDone := make([]chan struct{}, len(x.executions))
+	stderrTails := make([]*tailBuffer, len(x.executions))
+	for index, execution := range x.executions {
+		stderrDone[index] = make(chan struct{})
+		stderrTails[index] = newTailBuffer(stages[index].StderrTailSize)
+		go func(pipe io.ReadCloser, tail *tailBuffer, done chan struct{}) {
+			defer close(done)
+			if pipe == nil {
+				return
+			}
+			_, _ = io.Copy(&activityWriter{writer: tail, onActivity: x.touch}, pipe)
+			_ = pipe.Close()
+		}(execution.stdErrPipe, stderrTails[index], stderrDone[index])
+	}
+
+	go x.watch()
+
+	var waitGroup sync.WaitGroup
+	for index, execution := range x.executions {
+		waitGroup.Add(1)
+		go func(index int, execution *Execution) {
+			defer waitGroup.Done()
+
+			result, err := execution.Wait()
+			<-stderrDone[index]
+			result.Stderr = stderrTails[index].String()
+
+			x.results[index] = &PipelineStageResult{
+				Index:  index,
+				Name:   stages[index].execName,
+				Result: result,
+				Err:    err,
+			}
+			if err != nil && !x.brokenPipe(index, result) {
+				x.abort(index, err)
+			}
+		}(index, execution)
+	}
+	waitGroup.Wait()
+	close(x.stop)
+
+	x.mutex.Lock()
+	x.err = x.failedErr
+	x.mutex.Unlock()
+	if x.err == nil {
+		for _, result := range x.results {
+			if result.Err != nil && !x.brokenPipe(result.Index, result.Result) {
+				x.err = result.Err
+				break
+			}
+		}
+	}
+	close(x.done)
+}
+
+// brokenPipe 다음 단계가 먼저 끝나 SIGPIPE 로 종료된 경우 (ex: ... | head) 는 실패로 보지 않는다
+func (x *PipelineExecution) brokenPipe(index int, result *ExecutionResult) bool {
+	return index < len(x.executions)-1 && result.Signal == syscall.SIGPIPE.String()
+}
+
+// abort index 단계의 실패를 기록하고 나머지 단계를 종료
+func (x *PipelineExecution) abort(index int, err error) {
+
+	x.mutex.Lock()
+	if x.failedErr != nil {
+		x.mutex.Unlock()
+		return
+	}
+	x.failedErr = err
+	x.mutex.Unlock()
+
+	logger.Warn("pipeline stage failed. terminating other stages", "stage", index, "err", err)
+	cause := fmt.Errorf("pipeline aborted (stage %d failed) : %w", index, context.Canceled)
+	for other, execution := range x.executions {
+		if other != index {
+			execution.kill(cause)
+		}
+	}
+}
+
+// watch 전체 pipeline 의 HangTimeout 감시
+func (x *PipelineExecution) watch() {
+
+	hangTimeout := x.pipeline.HangTimeout
+	if hangTimeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(hangCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-x.stop:
+			return
+		case <-ticker.C:
+			if atomic.LoadInt64(&x.latestTime)+hangTimeout <= time.Now().Unix() {
+				logger.Warn("pipeline timed out", "hang_timeout", hangTimeout)
+				cause := fmt.Errorf("pipeline has no progress for %v seconds : %w", hangTimeout, errHangTimeout)
+				for _, execution := range x.executions {
+					execution.kill(cause)
+				}
+				return
+			}
+		}
+	}
+}
+
+// Wait 모든 단계가 종료될 때까지 기다린 후 단계별 결과 반환
+func (x *PipelineExecution) Wait() ([]*PipelineStageResult, error) {
+	<-x.done
+	return x.results, x.err
+}
+
+func (x *PipelineExecution) Done() <-chan struct{} {
+	return x.done
+}
+
+// Kill 모든 단계 종료. 결과는 ErrExecKilled
+func (x *PipelineExecution) Kill() {
+	for _, execution := range x.executions {
+		execution.kill(errKilled)
+	}
+}
+
+// Stages 단계별 Execution
+func (x *PipelineExecution) Stages() []*Execution {
+	return x.executions
+}
+
+// StdInPipe 첫 단계의 stdin (Stdin, StdinFile 이 설정된 경우 nil)
+func (x *PipelineExecution) StdInPipe() io.WriteCloser {
+	return x.executions[0].stdInPipe
+}
+
+// StdOutPipe 마지막 단계의 stdout (Stdout 이 설정된 경우 nil). 읽을 때마다 HangTimeout 을 갱신한다
+func (x *PipelineExecution) StdOutPipe() io.ReadCloser {
+	pipe := x.executions[len(x.executions)-1].stdOutPipe
+	if pipe == nil {
+		return nil
+	}
+	return &activityReader{ReadCloser: pipe, onActivity: x.touch}
+}
+
+type activityWriter struct {
+	writer     io.Writer
+	onActivity func()
+}
+
+func (w *activityWriter) Write(p []byte) (int, error) {
+	w.onActivity()
+	return w.writer.Write(p)
+}
+
+type activityReader struct {
+	io.ReadCloser
+	onActivity func()
+}
+
+func (r *activityReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.onActivity()
+	}
+	return n, err
+}
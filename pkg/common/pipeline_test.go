@@ -0,0 +1,226 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// shStage sh -c script 를 실행하는 단계
+func shStage(script string) *ExternalProgramExecutor {
+	executor := NewExternalProgramExecutor("sh", []string{"-c", script}, nil)
+	executor.KillGracePeriod = 200 * time.Millisecond
+	return executor
+}
+
+type pipelineRun struct {
+	results []*PipelineStageResult
+	err     error
+}
+
+// runPipeline 테스트가 멈추지 않도록 timeout 을 두고 Run
+func runPipeline(t *testing.T, pipeline *Pipeline) ([]*PipelineStageResult, error) {
+	t.Helper()
+	done := make(chan pipelineRun, 1)
+	go func() {
+		results, err := pipeline.Run(context.Background())
+		done <- pipelineRun{results: results, err: err}
+	}()
+	select {
+	case run := <-done:
+		return run.results, run.err
+	case <-time.After(10 * time.Second):
+		t.Fatal("pipeline did not finish")
+		return nil, nil
+	}
+}
+
+func TestPipelineRun(t *testing.T) {
+
+	stages := []*ExternalProgramExecutor{
+		shStage("printf 'b\\na\\nc\\n'; echo first >&2"),
+		NewExternalProgramExecutor("sort", nil, nil),
+		shStage("tr a-z A-Z; echo last >&2"),
+	}
+	results, err := runPipeline(t, NewPipeline(stages...))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(results) != len(stages) {
+		t.Fatalf("len(results) = %v, want %v", len(results), len(stages))
+	}
+	for index, result := range results {
+		if result.Index != index || result.Err != nil || result.Result.ExitCode != 0 {
+			t.Errorf("results[%v] = %+v, %v", index, result, result.Err)
+		}
+	}
+	if results[1].Name != "sort" {
+		t.Errorf("results[1].Name = %q, want sort", results[1].Name)
+	}
+	if got := results[2].Result.Stdout; got != "A\nB\nC\n" {
+		t.Errorf("Stdout = %q, want %q", got, "A\nB\nC\n")
+	}
+	if results[0].Result.Stderr != "first\n" || results[2].Result.Stderr != "last\n" {
+		t.Errorf("Stderr = %q, %q", results[0].Result.Stderr, results[2].Result.Stderr)
+	}
+
+	// 단계 연결은 복사본에 설정되므로 호출자의 executor 는 그대로
+	for index, stage := range stages {
+		if stage.Stdin != nil || stage.Stdout != nil {
+			t.Errorf("stages[%v] is modified : Stdin %v, Stdout %v", index, stage.Stdin, stage.Stdout)
+		}
+	}
+}
+
+func TestPipelineStageFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []*ExternalProgramExecutor
+		failed int
+	}{
+		{name: "last stage", stages: []*ExternalProgramExecutor{shStage("sleep 30"), shStage("exit 3")}, failed: 1},
+		{name: "first stage", stages: []*ExternalProgramExecutor{shStage("exit 3"), shStage("sleep 30")}, failed: 0},
+		{name: "middle stage", stages: []*ExternalProgramExecutor{shStage("sleep 30"), shStage("exit 3"), shStage("sleep 30")}, failed: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			started := time.Now()
+			results, err := runPipeline(t, NewPipeline(test.stages...))
+
+			// 처음 실패한 단계의 에러를 반환하고 나머지 단계는 종료된다
+			if !errors.Is(err, ErrExecNonZeroExit) {
+				t.Errorf("Run() error = %v, want ErrExecNonZeroExit", err)
+			}
+			if elapsed := time.Since(started); elapsed > 5*time.Second {
+				t.Errorf("other stages are not terminated (elapsed %v)", elapsed)
+			}
+			for index, result := range results {
+				want := ErrSigTerm
+				if index == test.failed {
+					want = ErrExecNonZeroExit
+				}
+				if !errors.Is(result.Err, want) {
+					t.Errorf("results[%v].Err = %v, want code %v", index, result.Err, want.Code())
+				}
+			}
+			if code := results[test.failed].Result.ExitCode; code != 3 {
+				t.Errorf("results[%v].ExitCode = %v, want 3", test.failed, code)
+			}
+		})
+	}
+}
+
+func TestPipelineBrokenPipe(t *testing.T) {
+
+	results, err := runPipeline(t, NewPipeline(NewExternalProgramExecutor("yes", nil, nil), shStage("head -n 1")))
+
+	// ... | head 로 앞 단계가 SIGPIPE 로 종료된 것은 실패가 아니다
+	if err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+	if signal := results[0].Result.Signal; signal != syscall.SIGPIPE.String() {
+		t.Errorf("results[0].Signal = %q, want %q", signal, syscall.SIGPIPE.String())
+	}
+	if results[1].Err != nil || results[1].Result.Stdout != "y\n" {
+		t.Errorf("results[1] = %q, %v", results[1].Result.Stdout, results[1].Err)
+	}
+
+	// 마지막 단계의 SIGPIPE 는 실패
+	_, err = runPipeline(t, NewPipeline(shStage("kill -PIPE $$")))
+	if !errors.Is(err, ErrExecKilled) {
+		t.Errorf("Run() with SIGPIPE on the last stage error = %v, want ErrExecKilled", err)
+	}
+}
+
+func TestPipelineHangTimeout(t *testing.T) {
+
+	pipeline := NewPipeline(shStage("sleep 30"), shStage("cat"))
+	pipeline.HangTimeout = 1
+
+	started := time.Now()
+	results, err := runPipeline(t, pipeline)
+	if !errors.Is(err, ErrExecTimeout) {
+		t.Errorf("Run() error = %v, want ErrExecTimeout", err)
+	}
+	for index, result := range results {
+		if !errors.Is(result.Err, ErrExecTimeout) {
+			t.Errorf("results[%v].Err = %v, want ErrExecTimeout", index, result.Err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < time.Second || elapsed > 5*time.Second {
+		t.Errorf("timed out after %v, want about HangTimeout", elapsed)
+	}
+
+	// 출력이 계속되면 HangTimeout 보다 오래 실행되어도 종료하지 않는다 (초 단위로 확인하므로 간격을 충분히 짧게)
+	pipeline = NewPipeline(shStage("for i in 1 2 3 4 5 6 7 8; do echo $i; sleep 0.3; done"), shStage("cat"))
+	pipeline.HangTimeout = 2
+	results, err = runPipeline(t, pipeline)
+	if err != nil || results[1].Result.Stdout != "1\n2\n3\n4\n5\n6\n7\n8\n" {
+		t.Errorf("Run() = %q, %v", results[1].Result.Stdout, err)
+	}
+}
+
+func TestPipelineKill(t *testing.T) {
+
+	execution, err := NewPipeline(shStage("sleep 30"), shStage("cat")).Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = execution.StdInPipe().Close()
+	execution.Kill()
+
+	select {
+	case <-execution.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("pipeline is not killed")
+	}
+	results, err := execution.Wait()
+	if !errors.Is(err, ErrExecKilled) {
+		t.Errorf("Wait() error = %v, want ErrExecKilled", err)
+	}
+	for index, result := range results {
+		if !errors.Is(result.Err, ErrExecKilled) {
+			t.Errorf("results[%v].Err = %v, want ErrExecKilled", index, result.Err)
+		}
+	}
+}
+
+func TestPipelineStartFailure(t *testing.T) {
+
+	// 뒤 단계의 시작에 실패하면 이미 시작한 단계는 종료된 후에 반환한다
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	started := time.Now()
+	execution, err := NewPipeline(
+		shStage("echo $$ > "+pidFile+"; exec sleep 30"),
+		NewExternalProgramExecutor("neptune-no-such-program", nil, nil),
+	).Start(context.Background())
+
+	if execution != nil || !errors.Is(err, ErrExecNotFound) {
+		t.Fatalf("Start() = %v, %v, want ErrExecNotFound", execution, err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Start() returned after %v", elapsed)
+	}
+	if content, err := ioutil.ReadFile(pidFile); err == nil {
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(content)))
+		if pid > 0 && syscall.Kill(pid, 0) != syscall.ESRCH {
+			_ = syscall.Kill(pid, syscall.SIGKILL)
+			t.Errorf("started stage (pid:%v) is still running", pid)
+		}
+	}
+
+	if _, err := NewPipeline().Start(context.Background()); !errors.Is(err, ErrExecFailed) {
+		t.Errorf("Start() of an empty pipeline error = %v, want ErrExecFailed", err)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 자식 프로세스를 별도의 process group 으로 실행 (자식이 생성한 프로세스까지 함께 종료하기 위함)
+func setProcessGroup(command *exec.Cmd) {
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.Setpgid = true
+}
+
+// signalProcessGroup process group 전체에 signal 전송
+func signalProcessGroup(command *exec.Cmd, signal syscall.Signal) error {
+	if command == nil || command.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-command.Process.Pid, signal)
+}
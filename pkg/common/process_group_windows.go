@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+package common
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup windows 는 process group 을 지원하지 않음
+func setProcessGroup(*exec.Cmd) {}
+
+// signalProcessGroup windows 는 signal 을 지원하지 않으므로 직접 실행한 프로세스만 종료
+func signalProcessGroup(command *exec.Cmd, _ syscall.Signal) error {
+	if command == nil || command.Process == nil {
+		return nil
+	}
+	return command.Process.Kill()
+}
@@ -0,0 +1,79 @@
+//go:build linux
+// +build linux
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// limitCommand rlimit 은 /bin/sh 의 ulimit 으로 설정한 후 프로그램을 exec 하므로, 프로그램이 실행되기 전부터 적용되고
+// 이후 생성되는 자식 프로세스에도 상속된다. 설정에 실패하면 shell 이 실패한 ulimit 을 반환된 pipe (reader) 로 알리고 126 으로 종료한다.
+// writer 는 Start 후에 닫아야 하며, 프로그램에는 전달되지 않는다 (exec 전에 닫음)
+func limitCommand(command *exec.Cmd, limits *ResourceLimits) (reader *os.File, writer *os.File, err error) {
+
+	if limits == nil {
+		return nil, nil, nil
+	}
+
+	// ulimit -v 는 KB 단위
+	addressSpace := limits.AddressSpace / KB
+	if limits.AddressSpace > 0 && addressSpace == 0 {
+		addressSpace = 1
+	}
+
+	// ExtraFiles 의 첫번째 fd 는 3
+	fd := 3 + len(command.ExtraFiles)
+
+	var script []string
+	for _, limit := range []struct {
+		option string
+		value  uint64
+	}{
+		{"-t", limits.CPUTime},
+		{"-v", addressSpace},
+		{"-n", limits.OpenFiles},
+	} {
+		if limit.value > 0 {
+			script = append(script, fmt.Sprintf("ulimit %v %v || { echo 'ulimit %v %v' >&%v; exit 126; }",
+				limit.option, limit.value, limit.option, limit.value, fd))
+		}
+	}
+	if len(script) == 0 {
+		return nil, nil, nil
+	}
+	script = append(script, fmt.Sprintf("exec %v>&-", fd), `exec "$0" "$@"`)
+
+	if reader, writer, err = os.Pipe(); err != nil {
+		return nil, nil, err
+	}
+	command.ExtraFiles = append(command.ExtraFiles, writer)
+	command.Args = append([]string{"sh", "-c", strings.Join(script, "; "), command.Path}, command.Args[1:]...)
+	command.Path = "/bin/sh"
+	return reader, writer, nil
+}
+
+// startCommand nice 는 thread 단위 속성이므로 nice 를 적용한 전용 thread 에서 fork 해서, 프로그램이 실행되기 전에 상속되도록 한다.
+// 이 thread 는 UnlockOSThread 없이 goroutine 이 끝나면서 폐기되어 다른 goroutine 에 영향을 주지 않는다
+func startCommand(command *exec.Cmd, nice int) error {
+
+	if nice == 0 {
+		return command.Start()
+	}
+
+	started := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, syscall.Gettid(), nice); err != nil {
+			started <- fmt.Errorf("setpriority error (nice:%v) : %w", nice, err)
+			return
+		}
+		started <- command.Start()
+	}()
+	return <-started
+}
@@ -0,0 +1,48 @@
+//go:build linux
+// +build linux
+
+package common
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   *ResourceLimits
+		script   string
+		stdout   string
+		exitCode int
+		want     *NeptuneError
+		message  string
+	}{
+		{name: "open files", limits: &ResourceLimits{OpenFiles: 64}, script: "ulimit -n", stdout: "64\n"},
+		{name: "cpu time", limits: &ResourceLimits{CPUTime: 30}, script: "ulimit -t", stdout: "30\n"},
+		{name: "address space", limits: &ResourceLimits{AddressSpace: 1024 * MB}, script: "ulimit -v", stdout: "1048576\n"},
+		{name: "fd not inherited", limits: &ResourceLimits{OpenFiles: 64}, script: "if (echo >&3) 2>/dev/null; then echo open; else echo closed; fi", stdout: "closed\n"},
+		{name: "program exit 126", limits: &ResourceLimits{OpenFiles: 64}, script: "exit 126", exitCode: 126, want: ErrExecNonZeroExit},
+		{name: "setup failure", limits: &ResourceLimits{OpenFiles: 1 << 40}, exitCode: 126, want: ErrExecFailed, message: "ulimit -n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor := NewExternalProgramExecutor("sh", []string{"-c", test.script}, nil)
+			executor.Limits = test.limits
+			result, err := executor.Run(context.Background())
+
+			if test.want == nil && err != nil || test.want != nil && !errors.Is(err, test.want) {
+				t.Fatalf("Run() error = %v, want %v", err, test.want)
+			}
+			if err != nil && !strings.Contains(err.Error(), test.message) {
+				t.Errorf("Run() error = %v, want containing %q", err, test.message)
+			}
+			if result.Stdout != test.stdout || result.ExitCode != test.exitCode {
+				t.Errorf("Stdout, ExitCode = %q, %v, want %q, %v", result.Stdout, result.ExitCode, test.stdout, test.exitCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package common
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// limitCommand rlimit 은 linux 에서만 지원
+func limitCommand(_ *exec.Cmd, limits *ResourceLimits) (*os.File, *os.File, error) {
+	if limits != nil {
+		return nil, nil, errors.New("resource limits are supported only on linux")
+	}
+	return nil, nil, nil
+}
+
+// startCommand nice 는 linux 에서만 지원
+func startCommand(command *exec.Cmd, nice int) error {
+	if nice != 0 {
+		return errors.New("nice is supported only on linux")
+	}
+	return command.Start()
+}
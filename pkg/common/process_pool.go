@@ -0,0 +1,529 @@
+package common
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultResourceCheckInterval 자원 부족으로 대기중인 job 의 재시도 주기
+const DefaultResourceCheckInterval = 5 * time.Second
+
+const (
+	ProcessJobQueued   = "queued"
+	ProcessJobRunning  = "running"
+	ProcessJobFinished = "finished"
+	ProcessJobFailed   = "failed"
+	ProcessJobCanceled = "canceled"
+)
+
+// ProcessPoolEvent job 상태 변경 event. WSServer.BroadcastJson 등으로 그대로 전달할 수 있다
+type ProcessPoolEvent struct {
+	Type      string    `json:"type"`
+	JobId     string    `json:"job_id"`
+	Priority  int       `json:"priority"`
+	Time      time.Time `json:"time"`
+	Pid       int       `json:"pid,omitempty"`
+	ExitCode  int       `json:"exit_code,omitempty"`
+	ErrorCode int       `json:"error_code,omitempty"`
+	Error     string    `json:"error,omitempty"`
+
+	// event 발생 시점의 pool 상태
+	Queued  int `json:"queued"`
+	Running int `json:"running"`
+}
+
+// ProcessJob ProcessPool 에서 실행할 외부 프로그램
+type ProcessJob struct {
+	Id string
+
+	// 클수록 먼저 실행 (같으면 먼저 등록된 job)
+	Priority int
+
+	Executor *ExternalProgramExecutor
+
+	// 시작 직후 호출 (pipe 처리, OnProgress 등). nil 이면 Run 처럼 stdout, stderr 를 결과에 저장하고 HangTimeout 은 사용하지 않는다
+	OnStart func(execution *Execution)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// queue 순서 및 heap index
+	sequence uint64
+	index    int
+
+	state      string
+	submitTime time.Time
+	startTime  time.Time
+	execution  *Execution
+
+	// dequeue 되면 닫힘
+	dequeued chan struct{}
+
+	done   chan struct{}
+	result *ExecutionResult
+	err    error
+}
+
+func NewProcessJob(id string, priority int, executor *ExternalProgramExecutor) *ProcessJob {
+	return &ProcessJob{
+		Id:       id,
+		Priority: priority,
+		Executor: executor,
+	}
+}
+
+// Wait 종료 (완료, 실패, 취소) 될 때까지 기다린 후 실행 결과 반환. 실행 전에 취소되면 결과는 nil
+func (j *ProcessJob) Wait() (*ExecutionResult, error) {
+	<-j.done
+	return j.result, j.err
+}
+
+func (j *ProcessJob) Done() <-chan struct{} {
+	return j.done
+}
+
+// Cancel 대기중이면 queue 에서 제거하고, 실행중이면 process group 을 종료
+func (j *ProcessJob) Cancel() {
+	j.cancel()
+}
+
+// ProcessJobInfo job 상태 조회 정보
+type ProcessJobInfo struct {
+	Id         string    `json:"id"`
+	Priority   int       `json:"priority"`
+	State      string    `json:"state"`
+	SubmitTime time.Time `json:"submit_time"`
+	StartTime  time.Time `json:"start_time,omitempty"`
+	Pid        int       `json:"pid,omitempty"`
+}
+
+// ProcessPoolState pool 상태. Queued 는 실행 순서대로 정렬
+type ProcessPoolState struct {
+	Slots   int               `json:"slots"`
+	Queued  []*ProcessJobInfo `json:"queued"`
+	Running []*ProcessJobInfo `json:"running"`
+
+	// 자원 부족으로 대기중인 이유 (없으면 빈 문자열)
+	Waiting string `json:"waiting,omitempty"`
+}
+
+// ProcessPool 외부 프로그램 job 을 우선 순위 queue 에 넣고, slot 수와 (선택적으로) 여유 CPU/메모리/디스크 만큼만 동시에 실행
+type ProcessPool struct {
+
+	// 아래 설정은 첫 Submit 전에 지정한다 (0 이면 검사하지 않음)
+
+	// CPULoad (1분 load average / CPU 수) 가 이 값 이상이면 대기
+	MaxCPULoad float64
+
+	// AvailableMemory 가 이 값 (bytes) 미만이면 대기
+	MinFreeMemory uint64
+
+	// DiskPath (기본값 ".") 의 DiskUsage Free 가 이 값 (bytes) 미만이면 대기
+	MinFreeDisk uint64
+	DiskPath    string
+
+	ResourceCheckInterval time.Duration
+
+	// job 상태 변경시 호출 (순서대로 하나씩 호출된다)
+	OnEvent func(event *ProcessPoolEvent)
+
+	mutex    sync.Mutex
+	slots    int
+	queue    processJobQueue
+	running  map[*ProcessJob]struct{}
+	sequence uint64
+	waiting  string
+	closed   bool
+
+	eventMutex sync.Mutex
+
+	startOnce sync.Once
+	wakeup    chan struct{}
+	stop      chan struct{}
+}
+
+// NewProcessPool slots 동시 실행 최대 수 (최소 1)
+func NewProcessPool(slots int) *ProcessPool {
+	if slots < 1 {
+		slots = 1
+	}
+	return &ProcessPool{
+		slots:                 slots,
+		running:               map[*ProcessJob]struct{}{},
+		DiskPath:              ".",
+		ResourceCheckInterval: DefaultResourceCheckInterval,
+		wakeup:                make(chan struct{}, 1),
+		stop:                  make(chan struct{}),
+	}
+}
+
+// Submit job 을 queue 에 추가. ctx 가 취소되면 job 도 취소된다. pool 이 종료되었으면 ErrProcessPoolClosed
+func (p *ProcessPool) Submit(ctx context.Context, job *ProcessJob) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p.startOnce.Do(func() {
+		go p.dispatch()
+	})
+
+	job.ctx, job.cancel = context.WithCancel(ctx)
+	job.dequeued = make(chan struct{})
+	job.done = make(chan struct{})
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		job.cancel()
+		return ErrProcessPoolClosed.Copy(fmt.Errorf("job %v rejected", job.Id))
+	}
+	p.sequence++
+	job.sequence = p.sequence
+	job.state = ProcessJobQueued
+	job.submitTime = time.Now()
+	heap.Push(&p.queue, job)
+	event := p.newEvent(ProcessJobQueued, job)
+	p.mutex.Unlock()
+
+	logger.Debug("process job queued", "job_id", job.Id, "priority", job.Priority)
+	p.emit(event)
+
+	// 대기중 취소
+	go func() {
+		select {
+		case <-job.ctx.Done():
+			p.cancelQueued(job, ErrSigTerm.Copy(job.ctx.Err()))
+		case <-job.dequeued:
+		}
+	}()
+
+	p.notify()
+	return nil
+}
+
+// Cancel id 의 job 을 취소. 대기중이거나 실행중인 job 이 없으면 false
+func (p *ProcessPool) Cancel(id string) bool {
+	p.mutex.Lock()
+	var jobs []*ProcessJob
+	for _, job := range p.queue {
+		if job.Id == id {
+			jobs = append(jobs, job)
+		}
+	}
+	for job := range p.running {
+		if job.Id == id {
+			jobs = append(jobs, job)
+		}
+	}
+	p.mutex.Unlock()
+
+	for _, job := range jobs {
+		job.Cancel()
+	}
+	return len(jobs) > 0
+}
+
+// SetSlots 동시 실행 최대 수 변경. 줄어드는 경우 실행중인 job 은 그대로 두고 새로 시작하지 않는다
+func (p *ProcessPool) SetSlots(slots int) {
+	if slots < 1 {
+		slots = 1
+	}
+	p.mutex.Lock()
+	p.slots = slots
+	p.mutex.Unlock()
+	p.notify()
+}
+
+func (p *ProcessPool) State() *ProcessPoolState {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	state := &ProcessPoolState{
+		Slots:   p.slots,
+		Queued:  []*ProcessJobInfo{},
+		Running: []*ProcessJobInfo{},
+		Waiting: p.waiting,
+	}
+
+	queued := append(processJobQueue{}, p.queue...)
+	sort.Slice(queued, queued.Less)
+	for _, job := range queued {
+		state.Queued = append(state.Queued, job.info())
+	}
+	for job := range p.running {
+		state.Running = append(state.Running, job.info())
+	}
+	return state
+}
+
+// Shutdown 새 job 을 거부하고 대기중인 job 은 ErrProcessPoolClosed 로 종료한 후, 실행중인 job 이 끝나기를 기다린다.
+// ctx 가 만료되면 실행중인 job 을 종료 (SIGTERM, KillGracePeriod 후 SIGKILL) 하고 ctx.Err() 반환
+func (p *ProcessPool) Shutdown(ctx context.Context) error {
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil
+	}
+	p.closed = true
+	queued := append(processJobQueue{}, p.queue...)
+	var running []*ProcessJob
+	for job := range p.running {
+		running = append(running, job)
+	}
+	p.mutex.Unlock()
+	close(p.stop)
+
+	for _, job := range queued {
+		p.cancelQueued(job, ErrProcessPoolClosed.Copy(fmt.Errorf("job %v canceled", job.Id)))
+	}
+
+	for _, job := range running {
+		select {
+		case <-job.Done():
+		case <-ctx.Done():
+			logger.Warn("process pool shutdown timed out. terminating running jobs")
+			for _, job := range running {
+				job.Cancel()
+			}
+			for _, job := range running {
+				<-job.Done()
+			}
+			return ctx.Err()
+		}
+	}
+
+	logger.Info("process pool shutdown gracefully")
+	return nil
+}
+
+func (p *ProcessPool) notify() {
+	select {
+	case p.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (p *ProcessPool) dispatch() {
+
+	ticker := time.NewTicker(p.ResourceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-p.wakeup:
+		case <-ticker.C:
+		}
+		p.startJobs()
+	}
+}
+
+// startJobs 빈 slot 과 자원이 있는 동안 우선 순위 순서대로 시작
+func (p *ProcessPool) startJobs() {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for !p.closed && p.queue.Len() > 0 && len(p.running) < p.slots {
+
+		waiting := p.checkResources()
+		if waiting != p.waiting && waiting != "" {
+			logger.Info("process pool is waiting for resources", "reason", waiting)
+		}
+		p.waiting = waiting
+		if waiting != "" {
+			break
+		}
+
+		job := heap.Pop(&p.queue).(*ProcessJob)
+		close(job.dequeued)
+		job.state = ProcessJobRunning
+		job.startTime = time.Now()
+		p.running[job] = struct{}{}
+		go p.run(job)
+	}
+}
+
+// checkResources 부족한 자원 설명 (충분하거나 조회할 수 없으면 빈 문자열)
+func (p *ProcessPool) checkResources() string {
+
+	if p.MaxCPULoad > 0 {
+		if load, err := CPULoad(); err == nil && load >= p.MaxCPULoad {
+			return fmt.Sprintf("cpu load %.2f >= %.2f", load, p.MaxCPULoad)
+		}
+	}
+	if p.MinFreeMemory > 0 {
+		if available, err := AvailableMemory(); err == nil && available < p.MinFreeMemory {
+			return fmt.Sprintf("free memory %v < %v", available, p.MinFreeMemory)
+		}
+	}
+	if p.MinFreeDisk > 0 {
+		if disk, err := DiskUsage(p.DiskPath); err == nil && disk.Free < p.MinFreeDisk {
+			return fmt.Sprintf("free disk %v < %v (path:%v)", disk.Free, p.MinFreeDisk, p.DiskPath)
+		}
+	}
+	return ""
+}
+
+func (p *ProcessPool) run(job *ProcessJob) {
+
+	capture := job.OnStart == nil
+	execution, err := job.Executor.start(job.ctx, capture, !capture)
+
+	p.mutex.Lock()
+	job.execution = execution
+	event := p.newEvent(ProcessJobRunning, job)
+	p.mutex.Unlock()
+
+	var result *ExecutionResult
+	if err == nil {
+		logger.Info("process job started", "job_id", job.Id, "pid", execution.Pid())
+		p.emit(event)
+		if job.OnStart != nil {
+			job.OnStart(execution)
+		}
+		result, err = execution.Wait()
+	} else {
+		result = job.Executor.result
+	}
+
+	p.finish(job, result, err)
+}
+
+func (p *ProcessPool) finish(job *ProcessJob, result *ExecutionResult, err error) {
+
+	p.mutex.Lock()
+	delete(p.running, job)
+	switch {
+	case err == nil:
+		job.state = ProcessJobFinished
+	case job.ctx.Err() != nil && !errors.Is(err, ErrExecTimeout):
+		job.state = ProcessJobCanceled
+	default:
+		job.state = ProcessJobFailed
+	}
+	job.result, job.err = result, err
+	event := p.newEvent(job.state, job)
+	p.mutex.Unlock()
+
+	logger.Info("process job done", "job_id", job.Id, "state", job.state, "err", err)
+	job.cancel()
+	close(job.done)
+	p.emit(event)
+	p.notify()
+}
+
+// cancelQueued 대기중인 job 을 queue 에서 제거하고 err 로 종료 (이미 시작되었으면 무시)
+func (p *ProcessPool) cancelQueued(job *ProcessJob, err error) {
+
+	p.mutex.Lock()
+	if job.state != ProcessJobQueued {
+		p.mutex.Unlock()
+		return
+	}
+	heap.Remove(&p.queue, job.index)
+	close(job.dequeued)
+	job.state = ProcessJobCanceled
+	job.err = err
+	event := p.newEvent(ProcessJobCanceled, job)
+	p.mutex.Unlock()
+
+	logger.Info("process job canceled before start", "job_id", job.Id, "err", err)
+	job.cancel()
+	close(job.done)
+	p.emit(event)
+}
+
+// newEvent mutex 를 잡은 상태에서 호출
+func (p *ProcessPool) newEvent(eventType string, job *ProcessJob) *ProcessPoolEvent {
+
+	event := &ProcessPoolEvent{
+		Type:     eventType,
+		JobId:    job.Id,
+		Priority: job.Priority,
+		Time:     time.Now(),
+		Queued:   p.queue.Len(),
+		Running:  len(p.running),
+	}
+	if job.execution != nil {
+		event.Pid = job.execution.Pid()
+	}
+	if job.result != nil {
+		event.ExitCode = job.result.ExitCode
+	}
+	if job.err != nil {
+		event.Error = job.err.Error()
+		var neptuneError *NeptuneError
+		if errors.As(job.err, &neptuneError) {
+			event.ErrorCode = neptuneError.Code()
+		}
+	}
+	return event
+}
+
+func (p *ProcessPool) emit(event *ProcessPoolEvent) {
+	if p.OnEvent == nil {
+		return
+	}
+	p.eventMutex.Lock()
+	defer p.eventMutex.Unlock()
+	p.OnEvent(event)
+}
+
+// info mutex 를 잡은 상태에서 호출
+func (j *ProcessJob) info() *ProcessJobInfo {
+	info := &ProcessJobInfo{
+		Id:         j.Id,
+		Priority:   j.Priority,
+		State:      j.state,
+		SubmitTime: j.submitTime,
+		StartTime:  j.startTime,
+	}
+	if j.execution != nil {
+		info.Pid = j.execution.Pid()
+	}
+	return info
+}
+
+// processJobQueue container/heap 우선 순위 queue (Priority 내림차순, 등록 순서 오름차순)
+type processJobQueue []*ProcessJob
+
+func (q processJobQueue) Len() int {
+	return len(q)
+}
+
+func (q processJobQueue) Less(i, j int) bool {
+	if q[i].Priority != q[j].Priority {
+		return q[i].Priority > q[j].Priority
+	}
+	return q[i].sequence < q[j].sequence
+}
+
+func (q processJobQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *processJobQueue) Push(x interface{}) {
+	job := x.(*ProcessJob)
+	job.index = len(*q)
+	*q = append(*q, job)
+}
+
+func (q *processJobQueue) Pop() interface{} {
+	old := *q
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	job.index = -1
+	return job
+}
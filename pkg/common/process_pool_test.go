@@ -0,0 +1,211 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestPool 테스트가 끝나면 실행중인 job 을 바로 종료하는 pool
+func newTestPool(t *testing.T, slots int) *ProcessPool {
+	pool := NewProcessPool(slots)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_ = pool.Shutdown(ctx)
+	})
+	return pool
+}
+
+// poolEvents OnEvent 로 받은 event 를 channel 로 전달
+func poolEvents(pool *ProcessPool) <-chan *ProcessPoolEvent {
+	events := make(chan *ProcessPoolEvent, 64)
+	pool.OnEvent = func(event *ProcessPoolEvent) {
+		events <- event
+	}
+	return events
+}
+
+// waitEvent jobId 의 eventType event 를 기다림
+func waitEvent(t *testing.T, events <-chan *ProcessPoolEvent, jobId string, eventType string) *ProcessPoolEvent {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.JobId == jobId && event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("no %v event for job %v", eventType, jobId)
+		}
+	}
+}
+
+func waitJob(t *testing.T, job *ProcessJob) (*ExecutionResult, error) {
+	t.Helper()
+	select {
+	case <-job.Done():
+	case <-time.After(10 * time.Second):
+		job.Cancel()
+		t.Fatalf("job %v did not finish", job.Id)
+	}
+	return job.Wait()
+}
+
+func TestProcessPoolPriority(t *testing.T) {
+
+	pool := newTestPool(t, 1)
+	events := poolEvents(pool)
+
+	// slot 을 차지하고 있는 동안 나머지 job 을 등록
+	blocker := NewProcessJob("blocker", 0, helperExecutor("sleep", "30"))
+	if err := pool.Submit(context.Background(), blocker); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, events, "blocker", ProcessJobRunning)
+
+	var jobs []*ProcessJob
+	for _, job := range []struct {
+		id       string
+		priority int
+	}{{"low", 1}, {"high", 10}, {"mid", 5}, {"high-later", 10}} {
+		processJob := NewProcessJob(job.id, job.priority, helperExecutor("exit", "0"))
+		if err := pool.Submit(context.Background(), processJob); err != nil {
+			t.Fatal(err)
+		}
+		jobs = append(jobs, processJob)
+	}
+
+	var queued []string
+	for _, info := range pool.State().Queued {
+		queued = append(queued, info.Id)
+	}
+	want := []string{"high", "high-later", "mid", "low"}
+	if !reflect.DeepEqual(queued, want) {
+		t.Errorf("State().Queued = %v, want %v", queued, want)
+	}
+
+	// blocker 를 취소하면 우선 순위 순서대로 하나씩 실행
+	if !pool.Cancel("blocker") {
+		t.Fatal("Cancel(blocker) = false")
+	}
+	if _, err := waitJob(t, blocker); !errors.Is(err, ErrSigTerm) {
+		t.Errorf("blocker error = %v, want ErrSigTerm", err)
+	}
+	for _, job := range jobs {
+		if _, err := waitJob(t, job); err != nil {
+			t.Errorf("job %v error = %v", job.Id, err)
+		}
+	}
+
+	var started []string
+	for len(events) > 0 {
+		if event := <-events; event.Type == ProcessJobRunning {
+			started = append(started, event.JobId)
+		}
+	}
+	if !reflect.DeepEqual(started, want) {
+		t.Errorf("start order = %v, want %v", started, want)
+	}
+}
+
+func TestProcessPoolCancelQueued(t *testing.T) {
+
+	pool := newTestPool(t, 1)
+	events := poolEvents(pool)
+
+	blocker := NewProcessJob("blocker", 0, helperExecutor("sleep", "30"))
+	if err := pool.Submit(context.Background(), blocker); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, events, "blocker", ProcessJobRunning)
+
+	// pool.Cancel 과 Submit ctx 취소
+	byId := NewProcessJob("by-id", 0, helperExecutor("exit", "0"))
+	ctx, cancel := context.WithCancel(context.Background())
+	byCtx := NewProcessJob("by-ctx", 0, helperExecutor("exit", "0"))
+	for _, submit := range []struct {
+		ctx context.Context
+		job *ProcessJob
+	}{{context.Background(), byId}, {ctx, byCtx}} {
+		if err := pool.Submit(submit.ctx, submit.job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Cancel("by-id")
+	cancel()
+
+	for _, job := range []*ProcessJob{byId, byCtx} {
+		result, err := waitJob(t, job)
+		if result != nil || !errors.Is(err, ErrSigTerm) {
+			t.Errorf("job %v = %v, %v, want nil, ErrSigTerm", job.Id, result, err)
+		}
+	}
+
+	// 두 job 의 canceled event 는 순서가 정해져 있지 않다
+	canceled := map[string]int{}
+	timeout := time.After(10 * time.Second)
+	for len(canceled) < 2 {
+		select {
+		case event := <-events:
+			if event.Type == ProcessJobCanceled {
+				canceled[event.JobId] = event.ErrorCode
+			}
+		case <-timeout:
+			t.Fatalf("canceled events = %v", canceled)
+		}
+	}
+	if want := map[string]int{"by-id": ErrSigTermCode, "by-ctx": ErrSigTermCode}; !reflect.DeepEqual(canceled, want) {
+		t.Errorf("canceled events (job : error code) = %v, want %v", canceled, want)
+	}
+	if state := pool.State(); len(state.Queued) != 0 || len(state.Running) != 1 {
+		t.Errorf("State() = %+v, want only blocker running", state)
+	}
+	if pool.Cancel("by-id") {
+		t.Error("Cancel(by-id) after cancel = true")
+	}
+}
+
+func TestProcessPoolShutdown(t *testing.T) {
+
+	pool := NewProcessPool(1)
+	events := poolEvents(pool)
+
+	running := NewProcessJob("running", 0, helperExecutor("sleep", "30"))
+	if err := pool.Submit(context.Background(), running); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, events, "running", ProcessJobRunning)
+	queued := NewProcessJob("queued", 0, helperExecutor("exit", "0"))
+	if err := pool.Submit(context.Background(), queued); err != nil {
+		t.Fatal(err)
+	}
+
+	// 만료된 ctx 면 실행중인 job 을 종료하고 반환
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	if _, err := waitJob(t, queued); !errors.Is(err, ErrProcessPoolClosed) {
+		t.Errorf("queued job error = %v, want ErrProcessPoolClosed", err)
+	}
+	if _, err := waitJob(t, running); !errors.Is(err, ErrSigTerm) {
+		t.Errorf("running job error = %v, want ErrSigTerm", err)
+	}
+
+	rejected := NewProcessJob("rejected", 0, helperExecutor("exit", "0"))
+	if err := pool.Submit(context.Background(), rejected); !errors.Is(err, ErrProcessPoolClosed) {
+		t.Errorf("Submit() after Shutdown error = %v, want ErrProcessPoolClosed", err)
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown() error = %v", err)
+	}
+}
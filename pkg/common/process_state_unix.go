@@ -0,0 +1,31 @@
+//go:build !windows
+// +build !windows
+
+package common
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// exitSignal signal 로 종료된 경우 signal 이름
+func exitSignal(state *os.ProcessState) string {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return status.Signal().String()
+	}
+	return ""
+}
+
+// peakRSS 최대 메모리 사용량 (bytes)
+func peakRSS(state *os.ProcessState) int64 {
+	usage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// linux 는 KB, darwin 은 bytes 단위
+	if runtime.GOOS == "darwin" {
+		return int64(usage.Maxrss)
+	}
+	return int64(usage.Maxrss) * KB
+}
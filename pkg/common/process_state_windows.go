@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package common
+
+import "os"
+
+func exitSignal(*os.ProcessState) string {
+	return ""
+}
+
+func peakRSS(*os.ProcessState) int64 {
+	return 0
+}
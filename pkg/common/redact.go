@@ -0,0 +1,138 @@
+package common
+
+import (
+	"reflect"
+)
+
+// RedactedValue secret 필드 대신 출력되는 값
+const RedactedValue = "******"
+
+// RedactSecrets `secret:"true"` tag 가 있는 필드를 가린 복사본 반환 (문자열은 RedactedValue, 그 외는 zero value).
+// 중첩된 struct, pointer, slice, array, map, interface 안의 값도 처리하며 secret 필드가 없으면 object 를 그대로 반환한다.
+// 순환 참조는 이미 복사한 pointer (map, slice) 를 다시 사용하여 복사본에서도 유지된다
+func RedactSecrets(object interface{}) interface{} {
+	value := reflect.ValueOf(object)
+	if !value.IsValid() || !hasSecret(value.Type(), map[reflect.Type]bool{}) {
+		return object
+	}
+	return (&redactor{visited: map[visitedKey]reflect.Value{}}).redact(value).Interface()
+}
+
+// hasSecret secret 필드를 포함할 수 있는 type 인지 여부 (interface 는 실제 값을 알 수 없으므로 true)
+func hasSecret(objectType reflect.Type, visited map[reflect.Type]bool) bool {
+	switch objectType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasSecret(objectType.Elem(), visited)
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+	default:
+		return false
+	}
+	if visited[objectType] {
+		return false
+	}
+	visited[objectType] = true
+	for index := 0; index < objectType.NumField(); index++ {
+		field := objectType.Field(index)
+		if field.Tag.Get("secret") == "true" || hasSecret(field.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// visitedKey 같은 주소라도 type 이 다르면 (struct 와 첫번째 필드) 다른 값
+type visitedKey struct {
+	pointer   uintptr
+	length    int
+	valueType reflect.Type
+}
+
+type redactor struct {
+	// 원본 pointer, map, slice 별 복사본 (cycle guard)
+	visited map[visitedKey]reflect.Value
+}
+
+func (r *redactor) redact(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() || !hasSecret(value.Type(), map[reflect.Type]bool{}) {
+			return value
+		}
+		key := visitedKey{pointer: value.Pointer(), valueType: value.Type()}
+		if copied, ok := r.visited[key]; ok {
+			return copied
+		}
+		copied := reflect.New(value.Type().Elem())
+		r.visited[key] = copied
+		copied.Elem().Set(r.redact(value.Elem()))
+		return copied
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(r.redact(value.Elem()))
+		return copied
+	case reflect.Slice:
+		if value.IsNil() || !hasSecret(value.Type(), map[reflect.Type]bool{}) {
+			return value
+		}
+		key := visitedKey{pointer: value.Pointer(), length: value.Len(), valueType: value.Type()}
+		if copied, ok := r.visited[key]; ok {
+			return copied
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		r.visited[key] = copied
+		for index := 0; index < value.Len(); index++ {
+			copied.Index(index).Set(r.redact(value.Index(index)))
+		}
+		return copied
+	case reflect.Array:
+		if !hasSecret(value.Type(), map[reflect.Type]bool{}) {
+			return value
+		}
+		copied := reflect.New(value.Type()).Elem()
+		for index := 0; index < value.Len(); index++ {
+			copied.Index(index).Set(r.redact(value.Index(index)))
+		}
+		return copied
+	case reflect.Map:
+		if value.IsNil() || !hasSecret(value.Type(), map[reflect.Type]bool{}) {
+			return value
+		}
+		key := visitedKey{pointer: value.Pointer(), valueType: value.Type()}
+		if copied, ok := r.visited[key]; ok {
+			return copied
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		r.visited[key] = copied
+		iterator := value.MapRange()
+		for iterator.Next() {
+			copied.SetMapIndex(iterator.Key(), r.redact(iterator.Value()))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		for index := 0; index < value.NumField(); index++ {
+			field := copied.Field(index)
+			if !field.CanSet() {
+				continue
+			}
+			if value.Type().Field(index).Tag.Get("secret") == "true" {
+				if field.Kind() == reflect.String && field.Len() > 0 {
+					field.SetString(RedactedValue)
+				} else {
+					field.Set(reflect.Zero(field.Type()))
+				}
+				continue
+			}
+			field.Set(r.redact(field))
+		}
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,124 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+type redactCredential struct {
+	User     string
+	Password string `secret:"true"`
+	Key      []byte `secret:"true"`
+}
+
+type redactConfig struct {
+	Name        string
+	Credential  redactCredential
+	Pointer     *redactCredential
+	Slice       []redactCredential
+	Array       [1]redactCredential
+	Map         map[string]*redactCredential
+	Interface   interface{}
+	EmptySecret string `secret:"true"`
+}
+
+type redactNode struct {
+	Token string `secret:"true"`
+	Next  *redactNode
+}
+
+func TestRedactSecrets(t *testing.T) {
+
+	newCredential := func() redactCredential {
+		return redactCredential{User: "neptune", Password: "password", Key: []byte("key")}
+	}
+	redacted := redactCredential{User: "neptune", Password: RedactedValue}
+
+	credential := newCredential()
+	original := redactConfig{
+		Name:       "config",
+		Credential: newCredential(),
+		Pointer:    &credential,
+		Slice:      []redactCredential{newCredential()},
+		Array:      [1]redactCredential{newCredential()},
+		Map:        map[string]*redactCredential{"primary": &credential},
+		Interface:  newCredential(),
+	}
+
+	got, ok := RedactSecrets(&original).(*redactConfig)
+	if !ok {
+		t.Fatalf("RedactSecrets() type = %T, want *redactConfig", RedactSecrets(&original))
+	}
+	want := redactConfig{
+		Name:       "config",
+		Credential: redacted,
+		Pointer:    &redacted,
+		Slice:      []redactCredential{redacted},
+		Array:      [1]redactCredential{redacted},
+		Map:        map[string]*redactCredential{"primary": &redacted},
+		Interface:  redacted,
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("RedactSecrets() = %+v, want %+v", *got, want)
+	}
+
+	// 같은 pointer 는 복사본에서도 같은 pointer
+	if got.Pointer != got.Map["primary"] {
+		t.Errorf("shared pointer is copied twice")
+	}
+
+	// 원본은 그대로
+	if original.Credential.Password != "password" || credential.Password != "password" ||
+		original.Slice[0].Password != "password" || original.Interface.(redactCredential).Password != "password" {
+		t.Errorf("original is modified : %+v", original)
+	}
+}
+
+func TestRedactSecretsCycle(t *testing.T) {
+
+	first := &redactNode{Token: "first"}
+	second := &redactNode{Token: "second", Next: first}
+	first.Next = second
+
+	got := RedactSecrets(first).(*redactNode)
+	if got == first || got.Token != RedactedValue || got.Next.Token != RedactedValue {
+		t.Fatalf("RedactSecrets() = %+v", got)
+	}
+	if got.Next.Next != got {
+		t.Errorf("cycle is not preserved in the copy")
+	}
+	if first.Token != "first" || second.Token != "second" {
+		t.Errorf("original is modified")
+	}
+}
+
+func TestRedactSecretsWithoutSecret(t *testing.T) {
+
+	type plain struct {
+		Name  string
+		Items []int
+	}
+	object := &plain{Name: "plain", Items: []int{1}}
+
+	tests := []struct {
+		name   string
+		object interface{}
+	}{
+		{name: "nil", object: nil},
+		{name: "string", object: "text"},
+		{name: "struct pointer", object: object},
+		{name: "map", object: map[string]int{"a": 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := RedactSecrets(test.object)
+			if !reflect.DeepEqual(got, test.object) {
+				t.Errorf("RedactSecrets() = %v, want %v", got, test.object)
+			}
+		})
+	}
+	if RedactSecrets(object).(*plain) != object {
+		t.Errorf("RedactSecrets() copied an object without secret fields")
+	}
+}
@@ -0,0 +1,115 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultRotatingFileSize RotatingFile 한 파일의 최대 크기 기본값
+const DefaultRotatingFileSize = 100 * MB
+
+// RotatingFile 크기가 MaxSize 를 넘으면 path.1, path.2 ... path.MaxBackups 로 밀어내며 새 파일에 쓰는 writer.
+// 여러 goroutine 에서 함께 사용할 수 있다
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mutex sync.Mutex
+	file  *os.File
+	size  int64
+}
+
+// NewRotatingFile maxSize 가 0 이하면 DefaultRotatingFileSize, maxBackups 가 0 이면 이전 파일을 보관하지 않는다.
+// 기존 파일이 있으면 이어서 쓴다
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+
+	if maxSize <= 0 {
+		maxSize = DefaultRotatingFileSize
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	rotatingFile := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := rotatingFile.open(); err != nil {
+		return nil, err
+	}
+	return rotatingFile, nil
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate mutex 를 잡은 상태에서 호출
+func (r *RotatingFile) rotate() error {
+
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+
+	if r.maxBackups > 0 {
+		for index := r.maxBackups - 1; index > 0; index-- {
+			_ = os.Rename(r.backupPath(index), r.backupPath(index+1))
+		}
+		if err := os.Rename(r.path, r.backupPath(1)); err != nil {
+			return fmt.Errorf("log file rotate error (path:%v) : %w", r.path, err)
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+func (r *RotatingFile) backupPath(index int) string {
+	return fmt.Sprintf("%v.%d", r.path, index)
+}
+
+func (r *RotatingFile) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "logs", "output.log")
+	rotatingFile, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, write := range []string{"11111\n", "2222\n", "33333\n", "44444\n", "55555\n"} {
+		if _, err := rotatingFile.Write([]byte(write)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rotatingFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 10 bytes 를 넘으면 밀어내고 최대 2개까지 보관
+	want := map[string]string{
+		path:        "55555\n",
+		path + ".1": "44444\n",
+		path + ".2": "33333\n",
+	}
+	for file, content := range want {
+		data, err := ioutil.ReadFile(file)
+		if err != nil || string(data) != content {
+			t.Errorf("%v = %q, %v, want %q", filepath.Base(file), data, err, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%v.3 exists", filepath.Base(path))
+	}
+
+	if _, err := rotatingFile.Write([]byte("closed")); err != os.ErrClosed {
+		t.Errorf("Write() after Close error = %v, want os.ErrClosed", err)
+	}
+
+	// 기존 파일이 있으면 이어서 쓴다
+	reopened, err := NewRotatingFile(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = reopened.Close()
+	}()
+	if _, err := reopened.Write([]byte("6\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != "55555\n6\n" {
+		t.Errorf("reopened file = %q", data)
+	}
+}
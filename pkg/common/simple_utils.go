@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -36,8 +35,9 @@ func ToJsonAsString(object interface{}) string {
 	return string(jsonBytes)
 }
 
+// ToJsonBeautifully 출력용이므로 `secret:"true"` 필드는 RedactSecrets 로 가린다
 func ToJsonBeautifully(object interface{}) string {
-	jsonBytes, _ := ToJson(object)
+	jsonBytes, _ := ToJson(RedactSecrets(object))
 	jsonString, _ := BeautifyJson(jsonBytes)
 	return jsonString
 }
@@ -76,14 +76,19 @@ func IF(is bool, trueValue interface{}, falseValue interface{}) interface{} {
 }
 
 type GoRoutineFunc func(ctx context.Context) interface{}
+
+// GoRoutineWithContext callback 들을 동시에 실행하고 반환값을 완료 순서로 모은다. error 반환값은 JoinResultErrors 로 모을 수 있다.
+// ctx 가 취소되면 바로 반환하며, 아직 실행 중인 callback 은 ctx 로 종료해야 한다
+//
+// Deprecated: 입력 순서대로 결과와 에러를 받을 수 있는 ParallelMap 혹은 Group 사용
 func GoRoutineWithContext(ctx context.Context, callbacks ...GoRoutineFunc) ([]interface{}, error) {
 
 	if callbacks == nil {
 		return nil, errors.New("callbacks is nil")
 	}
 
-	done := make(chan interface{})
-	defer close(done)
+	// ctx 취소로 먼저 반환해도 남은 goroutine 이 block 되지 않도록 callback 수만큼 buffer
+	done := make(chan interface{}, len(callbacks))
 
 	// execute go routine
 	for _, callback := range callbacks {
@@ -113,11 +118,11 @@ func GetMd5OfFile(inputFile string) (string, error) {
 	defer func() {
 		err := file.Close()
 		if err != nil {
-			log.Printf("file close err : %v", err)
+			logger.Warn("file close error", "path", inputFile, "err", err)
 		}
 	}()
 	if err != nil {
-		log.Printf("file (%v) open err : %v", inputFile, err)
+		logger.Error("file open error", "path", inputFile, "err", err)
 		return "", err
 	}
 
@@ -141,12 +146,13 @@ func DurationToSecond(duration string) (sec float64) {
 	return hours * (60 * 60) + minutes * (60) + seconds
 }
 
+// Deprecated: 1초 고정 대기로 모든 에러를 재시도하고 취소할 수 없다. retry.Do 사용
 func RetryWrapper(callback func() (interface{}, error), retryCount int) (interface{}, error) {
 	var err error
 	var result interface{}
 	for count := 0; count < retryCount; count++ {
 		if result, err = callback(); err != nil {
-			log.Printf("An error occurred. try again... (count:%v, error:%v)\n", count, err)
+			logger.Warn("an error occurred. try again", "count", count, "err", err)
 			// wait for a second
 			time.Sleep(time.Second)
 			continue
@@ -157,6 +163,7 @@ func RetryWrapper(callback func() (interface{}, error), retryCount int) (interfa
 	return nil, err
 }
 
+// Deprecated: 실행 파일 검색은 LookupExecPath 사용 (which/where 가 없는 환경에서도 동작)
 func GetWhereIs() string {
 	switch runtime.GOOS {
 	case "windows":
@@ -206,6 +213,7 @@ func QueryString(headers map[string]string) string {
 	return headerString
 }
 
+// Deprecated: ForEachBatch 사용
 func GroupLoop(originArray []interface{}, concurrencyCount int, callback func(group []interface{}) error) error {
 	count := len(originArray)
 	groupArray := originArray
@@ -217,9 +225,9 @@ func GroupLoop(originArray []interface{}, concurrencyCount int, callback func(gr
 			groupArray = originArray
 		}
 		if err := callback(groupArray); err != nil {
-			log.Printf("loop callback error : %v\n", err)
+			logger.Error("loop callback error", "err", err)
 			return err
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
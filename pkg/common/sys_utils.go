@@ -1,11 +1,19 @@
 package common
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 )
 
+// Shutdowner graceful shutdown 이 가능한 서버 (websock.WSServer, grpcwrapper.GrpcServer, ProcessPool)
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
 func WaitForShutdown(shutdownCallback func()) {
 
 	// set interrupt
@@ -19,6 +27,40 @@ func WaitForShutdown(shutdownCallback func()) {
 	shutdownCallback()
 }
 
+// WaitForShutdownInOrder interrupt/terminate 신호를 받으면 servers 를 등록 순서대로 종료.
+// timeout 은 전체 종료에 허용되는 시간이며 만료되면 남은 서버는 강제 종료된다
+func WaitForShutdownInOrder(timeout time.Duration, servers ...Shutdowner) error {
+
+	// set interrupt, terminate
+	sigChannel := make(chan os.Signal, 1)
+	signal.Notify(sigChannel, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChannel)
+
+	// wait
+	sig := <-sigChannel
+	logger.Info("shutdown signal received", "signal", sig)
+
+	return ShutdownInOrder(timeout, servers...)
+}
+
+// ShutdownInOrder servers 를 등록 순서대로 종료하고 첫번째 에러를 반환
+func ShutdownInOrder(timeout time.Duration, servers ...Shutdowner) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var firstErr error
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Error("server shutdown error", "server", fmt.Sprintf("%T", server), "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
 func GetCurrentTimeAsMilli() int64 {
 	return time.Now().UnixNano() / int64(time.Millisecond)
 }
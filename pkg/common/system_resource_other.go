@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package common
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// CPULoad linux 만 지원
+func CPULoad() (float64, error) {
+	return 0, fmt.Errorf("cpu load is not supported on %v", runtime.GOOS)
+}
+
+// AvailableMemory linux 만 지원
+func AvailableMemory() (uint64, error) {
+	return 0, fmt.Errorf("available memory is not supported on %v", runtime.GOOS)
+}
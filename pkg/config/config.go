@@ -0,0 +1,205 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// logger config package 로거 (logging.Configure("config", ...) 로 설정)
+var logger = logging.For("config")
+
+// Loader tag 가 있는 struct 를 기본값 < 파일 < 환경 변수 < command-line flag 순서로 채운다 (뒤의 것이 우선).
+//
+//	type ServerConfig struct {
+//		Port     int           `json:"port" env:"PORT" flag:"port" default:"8080" usage:"listen port"`
+//		DBUrl    string        `json:"db_url" env:"DB_URL" required:"true" secret:"true"`
+//		Timeout  time.Duration `json:"timeout" env:"TIMEOUT" default:"30s"`
+//	}
+//
+// 파일은 json tag 로 읽으며, 중첩된 struct 의 env, flag 이름에는 prefix 를 붙이지 않는다
+type Loader struct {
+	// 순서대로 읽는 설정 파일 (.json, .yaml, .yml). 없는 파일은 에러
+	Files []string
+
+	// 모든 env tag 앞에 붙일 prefix (ex: "NEPTUNE_")
+	EnvPrefix string
+
+	// flag tag 를 등록할 FlagSet. nil 이고 Args 도 nil 이면 flag 를 사용하지 않는다.
+	// Load 가 flag 를 등록한 후 parse 하므로 parse 되지 않은 FlagSet 을 전달해야 하며, 다시 Load 하면 등록된 flag 를 재사용한다
+	FlagSet *flag.FlagSet
+
+	// FlagSet 이 아직 parse 되지 않았을 때 사용할 인자 (nil 이면 os.Args[1:])
+	Args []string
+
+	// 환경 변수 조회 함수 (nil 이면 os.LookupEnv)
+	LookupEnv func(key string) (string, bool)
+}
+
+func NewLoader(files ...string) *Loader {
+	return &Loader{Files: files}
+}
+
+// Load 기본값, files, 환경 변수 순서로 target (struct pointer) 을 채운다
+func Load(target interface{}, files ...string) error {
+	return NewLoader(files...).Load(target)
+}
+
+// LoadEnv 기본값과 환경 변수로만 target 을 채운다
+func LoadEnv(target interface{}) error {
+	return NewLoader().Load(target)
+}
+
+// Load target (struct pointer) 을 채운 후 required 필드를 확인. 실패시 common.ErrConfigInvalid 반환
+func (l *Loader) Load(target interface{}) error {
+
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return common.ErrConfigInvalid.Copy(fmt.Errorf("config target must be a struct pointer (type:%T)", target))
+	}
+
+	fields := collectFields(value.Elem())
+
+	// defaults
+	for _, field := range fields {
+		if field.defaultValue == "" || !field.value.IsZero() {
+			continue
+		}
+		if err := setValue(field.value, field.defaultValue); err != nil {
+			return common.ErrConfigInvalid.Copy(fmt.Errorf("default value of %v : %w", field.name, err))
+		}
+	}
+
+	// files
+	for _, file := range l.Files {
+		if err := readFile(file, target); err != nil {
+			return common.ErrConfigInvalid.Copy(fmt.Errorf("config file %v : %w", file, err)).WithField("file", file)
+		}
+		logger.Debug("config file loaded", "file", file)
+	}
+
+	// environment variables
+	lookupEnv := l.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	for _, field := range fields {
+		if field.env == "" {
+			continue
+		}
+		env := l.EnvPrefix + field.env
+		if text, ok := lookupEnv(env); ok {
+			if err := setValue(field.value, text); err != nil {
+				return common.ErrConfigInvalid.Copy(fmt.Errorf("environment variable %v : %w", env, err)).WithField("env", env)
+			}
+		}
+	}
+
+	// flags
+	if err := l.applyFlags(fields); err != nil {
+		return err
+	}
+
+	return validate(fields)
+}
+
+func (l *Loader) applyFlags(fields []*configField) error {
+
+	flagSet, args := l.FlagSet, l.Args
+	if flagSet == nil {
+		if args == nil {
+			return nil
+		}
+		flagSet = flag.NewFlagSet("config", flag.ContinueOnError)
+	}
+
+	values, targets := map[string]*flagValue{}, map[string]*configField{}
+	for _, field := range fields {
+		if field.flag == "" {
+			continue
+		}
+		targets[field.flag] = field
+		// 이전 Load 에서 등록한 flag 는 다시 등록하지 않는다 (flag redefined panic)
+		if defined := flagSet.Lookup(field.flag); defined != nil {
+			value, ok := defined.Value.(*flagValue)
+			if !ok {
+				return common.ErrConfigInvalid.Copy(fmt.Errorf("flag -%v is already defined", field.flag)).WithField("flag", field.flag)
+			}
+			values[field.flag] = value
+			continue
+		}
+		// parse 후에 등록한 flag 는 무시되므로 에러
+		if flagSet.Parsed() {
+			return common.ErrConfigInvalid.Copy(fmt.Errorf("flag set is parsed before flag -%v is registered", field.flag)).WithField("flag", field.flag)
+		}
+		values[field.flag] = &flagValue{field: field}
+		flagSet.Var(values[field.flag], field.flag, field.usage)
+	}
+
+	if !flagSet.Parsed() {
+		if args == nil {
+			args = os.Args[1:]
+		}
+		if err := flagSet.Parse(args); err != nil {
+			return common.ErrConfigInvalid.Copy(err)
+		}
+	}
+
+	// 실제로 지정한 flag 만 적용 (재사용한 flagValue 는 이전 target 의 필드를 가리키므로 이번 target 의 필드에 설정)
+	var err error
+	flagSet.Visit(func(set *flag.Flag) {
+		if value, ok := values[set.Name]; ok && err == nil && value.set {
+			if setErr := setValue(targets[set.Name].value, value.text); setErr != nil {
+				err = common.ErrConfigInvalid.Copy(fmt.Errorf("flag -%v : %w", set.Name, setErr)).WithField("flag", set.Name)
+			}
+		}
+	})
+	return err
+}
+
+func validate(fields []*configField) error {
+	var missing []string
+	for _, field := range fields {
+		if field.required && field.value.IsZero() {
+			missing = append(missing, field.describe())
+		}
+	}
+	if len(missing) > 0 {
+		return common.ErrConfigInvalid.Copy(fmt.Errorf("required config missing : %v", strings.Join(missing, ", "))).
+			WithField("missing", missing)
+	}
+	return nil
+}
+
+// flagValue flag.Value. Parse 시점에는 값을 보관만 하고 환경 변수 적용 후에 필드에 설정한다
+type flagValue struct {
+	field *configField
+	text  string
+	set   bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil || f.field == nil {
+		return ""
+	}
+	return f.field.defaultValue
+}
+
+func (f *flagValue) Set(text string) error {
+	// 형식은 parse 할 때 바로 확인
+	probe := reflect.New(f.field.value.Type()).Elem()
+	if err := setValue(probe, text); err != nil {
+		return err
+	}
+	f.text, f.set = text, true
+	return nil
+}
+
+// IsBoolFlag bool 필드는 -verbose 처럼 값 없이 사용
+func (f *flagValue) IsBoolFlag() bool {
+	return f.field.value.Kind() == reflect.Bool
+}
@@ -0,0 +1,243 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDatabase struct {
+	Host string `json:"host" env:"DB_HOST" default:"localhost"`
+	Port int    `json:"port" env:"DB_PORT" flag:"db-port" default:"5432"`
+}
+
+type testConfig struct {
+	Port     int           `json:"port" env:"PORT" flag:"port" default:"8080" usage:"listen port"`
+	Name     string        `json:"name" env:"NAME" flag:"name" default:"neptune"`
+	Verbose  bool          `json:"verbose" env:"VERBOSE" flag:"verbose"`
+	Timeout  time.Duration `json:"timeout" env:"TIMEOUT" default:"30s"`
+	Tags     []string      `json:"tags" env:"TAGS"`
+	Database testDatabase  `json:"database"`
+}
+
+// mapEnv map 으로 환경 변수 조회
+func mapEnv(env map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+
+	jsonFile := writeConfigFile(t, "config.json", `{"port": 9000, "name": "from-json", "tags": ["a", "b"], "database": {"host": "json-db"}}`)
+	yamlFile := writeConfigFile(t, "config.yaml", "name: from-yaml\ndatabase:\n  port: 6000\n")
+
+	defaults := testConfig{Port: 8080, Name: "neptune", Timeout: 30 * time.Second, Database: testDatabase{Host: "localhost", Port: 5432}}
+
+	tests := []struct {
+		name  string
+		files []string
+		env   map[string]string
+		args  []string
+		want  func(config *testConfig)
+	}{
+		{name: "defaults", want: func(config *testConfig) {}},
+		{
+			name:  "files in order",
+			files: []string{jsonFile, yamlFile},
+			want: func(config *testConfig) {
+				config.Port, config.Name, config.Tags = 9000, "from-yaml", []string{"a", "b"}
+				config.Database = testDatabase{Host: "json-db", Port: 6000}
+			},
+		},
+		{
+			name:  "env over file",
+			files: []string{jsonFile},
+			env:   map[string]string{"APP_PORT": "9100", "APP_TIMEOUT": "1m", "APP_TAGS": "x, y", "APP_DB_HOST": "env-db", "PORT": "1"},
+			want: func(config *testConfig) {
+				config.Port, config.Name, config.Timeout, config.Tags = 9100, "from-json", time.Minute, []string{"x", "y"}
+				config.Database.Host = "env-db"
+			},
+		},
+		{
+			name:  "flag over env",
+			files: []string{jsonFile},
+			env:   map[string]string{"APP_PORT": "9100", "APP_NAME": "from-env"},
+			args:  []string{"-port", "9200", "-verbose", "-db-port=7000"},
+			want: func(config *testConfig) {
+				config.Port, config.Name, config.Verbose, config.Tags = 9200, "from-env", true, []string{"a", "b"}
+				config.Database = testDatabase{Host: "json-db", Port: 7000}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loader := &Loader{Files: test.files, EnvPrefix: "APP_", LookupEnv: mapEnv(test.env), Args: test.args}
+			var got testConfig
+			if err := loader.Load(&got); err != nil {
+				t.Fatal(err)
+			}
+			want := defaults
+			test.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+type durationConfig struct {
+	Timeout   time.Duration            `json:"timeout" default:"30s"`
+	Interval  *time.Duration           `json:"interval"`
+	Retries   []time.Duration          `json:"retries"`
+	Deadlines map[string]time.Duration `json:"deadlines"`
+	Database  struct {
+		Timeout time.Duration
+	} `json:"database"`
+}
+
+func TestLoaderFileDuration(t *testing.T) {
+
+	interval := 2 * time.Minute
+	want := durationConfig{
+		Timeout:   5 * time.Second,
+		Interval:  &interval,
+		Retries:   []time.Duration{time.Second, 1500 * time.Millisecond},
+		Deadlines: map[string]time.Duration{"upload": time.Hour},
+	}
+	want.Database.Timeout = 100 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "json",
+			file:    "config.json",
+			content: `{"timeout": "5s", "interval": "2m", "retries": ["1s", "1.5s"], "deadlines": {"upload": "1h"}, "database": {"timeout": "100ms"}}`,
+		},
+		{
+			name:    "yaml",
+			file:    "config.yaml",
+			content: "timeout: 5s\ninterval: 2m\nretries: [1s, 1.5s]\ndeadlines:\n  upload: 1h\ndatabase:\n  timeout: 100ms\n",
+		},
+		{
+			name:    "nanoseconds",
+			file:    "config.json",
+			content: `{"timeout": 5000000000, "interval": "2m", "retries": ["1s", 1500000000], "deadlines": {"upload": "1h"}, "database": {"Timeout": "100ms"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got durationConfig
+			if err := Load(&got, writeConfigFile(t, test.file, test.content)); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	// 형식이 잘못되면 필드 위치를 포함한 에러
+	err := Load(&durationConfig{}, writeConfigFile(t, "config.yml", "retries: [1s, soon]\n"))
+	if !errors.Is(err, common.ErrConfigInvalid) || !strings.Contains(err.Error(), "retries[1]") {
+		t.Errorf("Load() error = %v, want ErrConfigInvalid containing retries[1]", err)
+	}
+}
+
+type requiredConfig struct {
+	DBUrl  string `json:"db_url" env:"DB_URL" required:"true" secret:"true"`
+	Token  string `env:"TOKEN" flag:"token" required:"true"`
+	Region string `env:"REGION" default:"ap-northeast-2" required:"true"`
+}
+
+func TestLoaderErrors(t *testing.T) {
+
+	badJson := writeConfigFile(t, "bad.json", `{"port": "not a number"`)
+	unknownFormat := writeConfigFile(t, "config.toml", `port = 1`)
+
+	tests := []struct {
+		name    string
+		loader  *Loader
+		target  interface{}
+		message string
+	}{
+		{name: "not a pointer", loader: &Loader{}, target: testConfig{}, message: "struct pointer"},
+		{name: "missing file", loader: &Loader{Files: []string{"testdata/missing.json"}}, target: &testConfig{}, message: "missing.json"},
+		{name: "malformed file", loader: &Loader{Files: []string{badJson}}, target: &testConfig{}, message: "bad.json"},
+		{name: "unknown format", loader: &Loader{Files: []string{unknownFormat}}, target: &testConfig{}, message: "unsupported"},
+		{name: "invalid env", loader: &Loader{LookupEnv: mapEnv(map[string]string{"TIMEOUT": "soon"})}, target: &testConfig{}, message: "TIMEOUT"},
+		{name: "invalid flag", loader: &Loader{Args: []string{"-port", "http"}, LookupEnv: mapEnv(nil)}, target: &testConfig{}, message: "port"},
+		{name: "required", loader: &Loader{LookupEnv: mapEnv(nil)}, target: &requiredConfig{}, message: "DBUrl (env DB_URL), Token (env TOKEN, flag -token)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.loader.Load(test.target)
+			if !errors.Is(err, common.ErrConfigInvalid) || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("Load() error = %v, want ErrConfigInvalid containing %q", err, test.message)
+			}
+		})
+	}
+
+	// 모두 설정되면 성공
+	var config requiredConfig
+	loader := &Loader{LookupEnv: mapEnv(map[string]string{"DB_URL": "postgres://db", "TOKEN": "token"})}
+	if err := loader.Load(&config); err != nil || config.Region != "ap-northeast-2" {
+		t.Errorf("Load() = %+v, %v", config, err)
+	}
+}
+
+func TestLoaderFlagSet(t *testing.T) {
+
+	// 같은 FlagSet 으로 다시 Load 해도 flag 를 다시 등록하지 않는다
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := &Loader{FlagSet: flagSet, Args: []string{"-port", "9300"}, LookupEnv: mapEnv(nil)}
+	for count := 0; count < 2; count++ {
+		var config testConfig
+		if err := loader.Load(&config); err != nil || config.Port != 9300 {
+			t.Fatalf("Load() #%v = %v, %v", count+1, config.Port, err)
+		}
+	}
+	if usage := flagSet.Lookup("port"); usage == nil || usage.Usage != "listen port" || usage.DefValue != "8080" {
+		t.Errorf("flag -port = %+v", usage)
+	}
+
+	// 이미 parse 된 FlagSet 에는 등록할 수 없다
+	parsed := flag.NewFlagSet("parsed", flag.ContinueOnError)
+	if err := parsed.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	err := (&Loader{FlagSet: parsed, LookupEnv: mapEnv(nil)}).Load(&testConfig{})
+	if !errors.Is(err, common.ErrConfigInvalid) {
+		t.Errorf("Load() with parsed FlagSet error = %v, want ErrConfigInvalid", err)
+	}
+
+	// 다른 용도로 정의된 flag 와 이름이 같으면 에러
+	conflict := flag.NewFlagSet("conflict", flag.ContinueOnError)
+	conflict.String("port", "", "")
+	err = (&Loader{FlagSet: conflict, Args: []string{}, LookupEnv: mapEnv(nil)}).Load(&testConfig{})
+	if !errors.Is(err, common.ErrConfigInvalid) {
+		t.Errorf("Load() with conflicting flag error = %v, want ErrConfigInvalid", err)
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configField tag 가 있는 struct 필드
+type configField struct {
+	name  string
+	value reflect.Value
+
+	env          string
+	flag         string
+	defaultValue string
+	usage        string
+	required     bool
+}
+
+func (f *configField) describe() string {
+	var sources []string
+	if f.env != "" {
+		sources = append(sources, "env "+f.env)
+	}
+	if f.flag != "" {
+		sources = append(sources, "flag -"+f.flag)
+	}
+	if len(sources) == 0 {
+		return f.name
+	}
+	return fmt.Sprintf("%v (%v)", f.name, strings.Join(sources, ", "))
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// collectFields 중첩된 struct (pointer 는 nil 이면 생성) 까지 설정 가능한 필드 수집
+func collectFields(value reflect.Value) []*configField {
+	return appendFields(nil, value, "")
+}
+
+func appendFields(fields []*configField, value reflect.Value, prefix string) []*configField {
+
+	valueType := value.Type()
+	for index := 0; index < valueType.NumField(); index++ {
+		structField := valueType.Field(index)
+		if structField.PkgPath != "" {
+			continue
+		}
+		fieldValue := value.Field(index)
+		name := prefix + structField.Name
+
+		// 중첩 struct (TextUnmarshaler, time 관련 타입 제외)
+		if isNested(structField.Type) {
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(structField.Type.Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			fields = appendFields(fields, fieldValue, name+".")
+			continue
+		}
+
+		tag := structField.Tag
+		field := &configField{
+			name:         name,
+			value:        fieldValue,
+			env:          tag.Get("env"),
+			flag:         tag.Get("flag"),
+			defaultValue: tag.Get("default"),
+			usage:        tag.Get("usage"),
+			required:     tag.Get("required") == "true",
+		}
+		if field.env != "" || field.flag != "" || field.defaultValue != "" || field.required {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func isNested(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.Struct &&
+		!reflect.PtrTo(fieldType).Implements(textUnmarshalerType) &&
+		fieldType != reflect.TypeOf(time.Time{})
+}
+
+// setValue text 를 value 의 타입으로 변환해서 설정. slice 는 쉼표로 구분
+func setValue(value reflect.Value, text string) error {
+
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == reflect.TypeOf(time.Duration(0)) {
+			parsed, err := time.ParseDuration(text)
+			if err != nil {
+				return err
+			}
+			value.SetInt(int64(parsed))
+			return nil
+		}
+		parsed, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(text, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		var items []string
+		if strings.TrimSpace(text) != "" {
+			items = strings.Split(text, ",")
+		}
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for index, item := range items {
+			if err := setValue(slice.Index(index), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	case reflect.Ptr:
+		element := reflect.New(value.Type().Elem())
+		if err := setValue(element.Elem(), text); err != nil {
+			return err
+		}
+		value.Set(element)
+	default:
+		return fmt.Errorf("unsupported config field type : %v", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// readFile 확장자로 형식을 정한다. yaml 은 json 으로 변환해서 읽으므로 json tag 를 그대로 사용한다.
+// time.Duration 필드는 환경 변수, flag 와 같이 "30s" 형식의 문자열로 지정한다 (숫자는 nanoseconds)
+func readFile(path string, target interface{}) error {
+
+	fileBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var document interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(fileBytes))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return err
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(fileBytes, &document); err != nil {
+			return err
+		}
+		document = toJsonCompatible(document)
+	default:
+		return fmt.Errorf("unsupported config file format (path:%v)", path)
+	}
+	if document == nil {
+		return nil
+	}
+
+	if document, err = parseDurations(document, reflect.TypeOf(target), ""); err != nil {
+		return err
+	}
+	jsonBytes, err := common.ToJson(document)
+	if err != nil {
+		return err
+	}
+	return common.FromJson(jsonBytes, target)
+}
+
+// toJsonCompatible yaml.v2 의 map[interface{}]interface{} 를 map[string]interface{} 로 변환
+func toJsonCompatible(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			converted[fmt.Sprint(key)] = toJsonCompatible(item)
+		}
+		return converted
+	case []interface{}:
+		for index, item := range typed {
+			typed[index] = toJsonCompatible(item)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// parseDurations targetType 을 따라가며 time.Duration 위치의 문자열을 nanoseconds 로 변환 (encoding/json 은 문자열을 읽지 못함)
+func parseDurations(document interface{}, targetType reflect.Type, path string) (interface{}, error) {
+
+	for targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+
+	switch targetType.Kind() {
+	case reflect.Int64:
+		if text, ok := document.(string); ok && targetType == durationType {
+			duration, err := time.ParseDuration(text)
+			if err != nil {
+				return nil, fmt.Errorf("%v : %w", path, err)
+			}
+			return int64(duration), nil
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := document.([]interface{}); ok {
+			for index, item := range items {
+				parsed, err := parseDurations(item, targetType.Elem(), fmt.Sprintf("%v[%v]", path, index))
+				if err != nil {
+					return nil, err
+				}
+				items[index] = parsed
+			}
+		}
+	case reflect.Map:
+		if object, ok := document.(map[string]interface{}); ok {
+			for key, item := range object {
+				parsed, err := parseDurations(item, targetType.Elem(), joinPath(path, key))
+				if err != nil {
+					return nil, err
+				}
+				object[key] = parsed
+			}
+		}
+	case reflect.Struct:
+		if object, ok := document.(map[string]interface{}); ok {
+			if err := parseStructDurations(object, targetType, path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return document, nil
+}
+
+func parseStructDurations(object map[string]interface{}, structType reflect.Type, path string) error {
+
+	for index := 0; index < structType.NumField(); index++ {
+		structField := structType.Field(index)
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		// 이름 없는 embedded struct 의 필드는 같은 object 에 있다
+		if structField.Anonymous && name == "" {
+			embeddedType := structField.Type
+			if embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				if err := parseStructDurations(object, embeddedType, path); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if structField.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+
+		// encoding/json 과 같이 key 는 대소문자를 구분하지 않는다
+		for key, item := range object {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			parsed, err := parseDurations(item, structField.Type, joinPath(path, key))
+			if err != nil {
+				return err
+			}
+			object[key] = parsed
+		}
+	}
+	return nil
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
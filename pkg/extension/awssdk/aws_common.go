@@ -1,32 +1,44 @@
 package awssdk
 
 import (
-	"os"
+	"github.com/hwangtaeseung/neptune-core/pkg/config"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
+	"github.com/hwangtaeseung/neptune-core/pkg/retry"
+	"time"
 )
+
+// logger awssdk package 로거 (logging.Configure("awssdk", ...) 로 설정)
+var logger = logging.For("awssdk")
+
+// RetryPolicy S3 download, upload 재시도 정책. 기본은 기존과 같이 1초 간격으로 재시도 (OnAttempt 가 nil 이면 실패한 시도를 로그로 남긴다)
+var RetryPolicy = retry.ConstantPolicy(time.Second)
+
+func retryPolicy(name string) *retry.Policy {
+	policy := *RetryPolicy
+	if policy.OnAttempt == nil {
+		policy.OnAttempt = retry.LogAttempt(name)
+	}
+	return &policy
+}
+
 type S3Url struct {
-	Region                  string `json:"region"`
-	InputBucket             string `json:"input_bucket"`
-	TempBucket              string `json:"temp_bucket"`
-	OutputBucket            string `json:"output_bucket"`
-	SystemSettingsBucket    string `json:"setting_bucket"`
-	Key                     string `json:"key"`
-	MediaId 				string `json:"media_id"`
-	EncodingProfileForVideo string `json:"encoding_profile_for_video"`
-	AudioType               string `json:"audio_type"`
-	EncodingProfileForAudio string `json:"encoding_profile_for_audio"`
+	Region                  string `json:"region" env:"AWS_S3_REGION"`
+	InputBucket             string `json:"input_bucket" env:"AWS_S3_INPUT_BUCKET"`
+	TempBucket              string `json:"temp_bucket" env:"AWS_S3_TEMP_BUCKET"`
+	OutputBucket            string `json:"output_bucket" env:"AWS_S3_OUTPUT_BUCKET"`
+	SystemSettingsBucket    string `json:"setting_bucket" env:"AWS_S3_SYSTEM_SETTINGS_BUCKET"`
+	Key                     string `json:"key" env:"AWS_S3_KEY"`
+	MediaId                 string `json:"media_id" env:"MEDIA_ID"`
+	EncodingProfileForVideo string `json:"encoding_profile_for_video" env:"ENCODING_PROFILE_VIDEO"`
+	AudioType               string `json:"audio_type" env:"AUDIO_TYPE"`
+	EncodingProfileForAudio string `json:"encoding_profile_for_audio" env:"ENCODING_PROFILE_AUDIO"`
 }
 
+// GetS3UrlFromEnv env tag 의 환경 변수로 S3Url 생성 (파일, flag 와 함께 읽을 때는 config.Loader 사용)
 func GetS3UrlFromEnv() *S3Url {
-	return &S3Url{
-		Region:                  os.Getenv("AWS_S3_REGION"),
-		InputBucket:             os.Getenv("AWS_S3_INPUT_BUCKET"),
-		TempBucket:              os.Getenv("AWS_S3_TEMP_BUCKET"),
-		OutputBucket:            os.Getenv("AWS_S3_OUTPUT_BUCKET"),
-		SystemSettingsBucket:    os.Getenv("AWS_S3_SYSTEM_SETTINGS_BUCKET"),
-		Key:                     os.Getenv("AWS_S3_KEY"),
-		MediaId:                 os.Getenv("MEDIA_ID"),
-		EncodingProfileForVideo: os.Getenv("ENCODING_PROFILE_VIDEO"),
-		AudioType:               os.Getenv("AUDIO_TYPE"),
-		EncodingProfileForAudio: os.Getenv("ENCODING_PROFILE_AUDIO"),
+	s3Url := &S3Url{}
+	if err := config.LoadEnv(s3Url); err != nil {
+		logger.Error("s3 url config load failed", "err", err)
 	}
-}
\ No newline at end of file
+	return s3Url
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/batch"
-	"log"
 	"github.com/hwangtaeseung/neptune-core/pkg/common"
 )
 
@@ -28,10 +27,10 @@ func SubmitJob(region, jobDefinition, jobName, jobQueue string, envs []*batch.Ke
 
 	service := batch.New(sess)
 	if output, err := service.SubmitJob(input); err != nil {
-		log.Printf("job submit error : %v", err)
+		logger.Error("job submit error", "err", err)
 		return nil, err
 	} else {
-		log.Printf("job submit ok : %v", common.ToJsonAsString(output))
+		logger.Info("job submit ok", "output", common.ToJsonAsString(output))
 		return output, nil
 	}
 }
@@ -49,10 +48,10 @@ func TerminateJob(region, jobId, reason string) (*batch.TerminateJobOutput, erro
 
 	service := batch.New(sess)
 	if output, err := service.TerminateJob(input); err != nil {
-		log.Printf("terminate job error : %v", err)
+		logger.Error("terminate job error", "err", err)
 		return nil, err
 	} else {
-		log.Printf("terminate job ok : %v", common.ToJsonAsString(output))
+		logger.Info("terminate job ok", "output", common.ToJsonAsString(output))
 		return output, nil
 	}
 }
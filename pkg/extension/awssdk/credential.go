@@ -1,7 +1,6 @@
 package awssdk
 
 import (
-	"log"
 	"github.com/hwangtaeseung/neptune-core/pkg/common"
 )
 
@@ -14,14 +13,14 @@ func GetAwsCredential(s3Url *S3Url) (*AWSCredential, error) {
 
 	downloadedFile, _, err := DownloadS3(s3Url, ".", nil, nil)
 	if err != nil {
-		log.Printf("credential file downloading error : %v\n", err)
+		logger.Error("credential file downloading error", "err", err)
 		return nil, err
 	}
 
 	var credential AWSCredential
 	err = common.JsonFileToObject(downloadedFile, &credential)
 	if err != nil {
-		log.Printf("credential file parsong error : %v\n", err)
+		logger.Error("credential file parsing error", "path", downloadedFile, "err", err)
 		return nil, err
 	}
 
@@ -4,7 +4,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"log"
 )
 
 func GetKeysInBucket(region string, bucket string) ([]string, error) {
@@ -24,7 +23,7 @@ func GetKeysInBucket(region string, bucket string) ([]string, error) {
 		return lastPage
 	})
 	if err != nil {
-		log.Printf("err : %v\n", err)
+		logger.Error("list objects error", "bucket", bucket, "err", err)
 		return nil, err
 	}
 	return fileList, nil
@@ -48,7 +47,7 @@ func GetKeysInBucketPrefix(region, bucket, prefix string) ([]string, error) {
 		return lastPage
 	})
 	if err != nil {
-		log.Printf("err : %v\n", err)
+		logger.Error("list objects error", "bucket", bucket, "prefix", prefix, "err", err)
 		return nil, err
 	}
 	return fileList, nil
@@ -1,11 +1,12 @@
 package awssdk
 
 import (
+	"context"
 	"fmt"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/retry"
 	"io"
 	"io/ioutil"
-	"log"
-	"github.com/hwangtaeseung/neptune-core/pkg/common"
 	"os"
 	"path/filepath"
 	"sync/atomic"
@@ -41,14 +42,14 @@ func DownloadS3Partially(s3Url *S3Url, tempDir string, partialRange string,
 			return
 		}
 		if err := temp.Close(); err != nil {
-			log.Printf("temporary file close error.")
+			logger.Warn("temporary file close error", "err", err)
 		} else {
-			log.Printf("temporary file close success.")
+			logger.Debug("temporary file close success")
 		}
 	}()
 
 	tempFileName := temp.Name()
-	log.Printf("temp file name : %v\n", tempFileName)
+	logger.Debug("temp file created", "path", tempFileName)
 
 	params := &s3.GetObjectInput{
 		Bucket: aws.String(s3Url.InputBucket),
@@ -59,22 +60,21 @@ func DownloadS3Partially(s3Url *S3Url, tempDir string, partialRange string,
 	downloader := s3manager.NewDownloader(sess)
 
 	var downloadedSize int64
-	for count := 0; count < common.DefaultMaxRetryCount; count++ {
+	err = retry.Do(context.Background(), retryPolicy("s3 download"), func(ctx context.Context) error {
 
 		client := s3.New(sess)
-		var fileSize int64
-		fileSize, err = getS3FileSize(client, s3Url.InputBucket, s3Url.Key)
+		fileSize, err := getS3FileSize(client, s3Url.InputBucket, s3Url.Key)
 		if err != nil {
-			log.Printf("get file size error. (err:%v, s3Url:%+v). retry %d times \n", err, s3Url, count)
-			// delay...
-			time.Sleep(time.Second)
-			continue
+			return fmt.Errorf("get file size error (s3Url:%+v) : %w", s3Url, err)
 		}
 
 		wd, _ := os.Getwd()
 		disk, err := common.DiskUsage(wd)
-		if float64(disk.Free) * 0.95 < float64(fileSize) {
-			return "", 0, fmt.Errorf("out of disk space (downloadFileSize:%v, diskDize:%v)", fileSize, disk.Free)
+		if err != nil {
+			return err
+		}
+		if float64(disk.Free)*0.95 < float64(fileSize) {
+			return retry.Permanent(fmt.Errorf("out of disk space (downloadFileSize:%v, diskDize:%v)", fileSize, disk.Free))
 		}
 
 		writer := &progressWriter{
@@ -84,36 +84,30 @@ func DownloadS3Partially(s3Url *S3Url, tempDir string, partialRange string,
 			callback: progressCallback,
 		}
 
-		downloadedSize, err = downloader.Download(writer, params)
-		if err != nil {
-			log.Printf("Download failed! retry %d times\n", count+1)
-
-			// delay...
-			time.Sleep(time.Second)
+		downloadedSize, err = downloader.DownloadWithContext(ctx, writer, params)
+		return err
+	})
+	if err != nil {
+		logger.Error("s3 download failed", "bucket", s3Url.InputBucket, "key", s3Url.Key, "err", err)
+		return "", 0, err
+	}
 
-		} else {
-			// downloading complete
-			if endCallback != nil {
-				endCallback(downloadedSize)
-			}
-			log.Printf("downlod return value : %v, %v", downloadedSize, tempFileName)
-
-			// rename temp file name
-			_, fileName := filepath.Split(s3Url.Key)
-			fileName = fmt.Sprintf("%v/%v", tempDir, fileName)
-			if err := os.Rename(temp.Name(), fileName); err != nil {
-				log.Printf("rename error")
-				return "", 0, err
-			}
-			log.Println("File downloaded! Available at:", fileName)
-
-			return fileName, downloadedSize, nil
-		}
+	// downloading complete
+	if endCallback != nil {
+		endCallback(downloadedSize)
 	}
+	logger.Debug("s3 download complete", "size", downloadedSize, "path", tempFileName)
 
-	log.Printf("Download failed finally!! (err:%v)\n", err)
+	// rename temp file name
+	_, fileName := filepath.Split(s3Url.Key)
+	fileName = fmt.Sprintf("%v/%v", tempDir, fileName)
+	if err := os.Rename(temp.Name(), fileName); err != nil {
+		logger.Error("rename error", "from", temp.Name(), "to", fileName, "err", err)
+		return "", 0, err
+	}
+	logger.Info("file downloaded", "path", fileName)
 
-	return "", 0, err
+	return fileName, downloadedSize, nil
 }
 
 /////////////////////////
@@ -174,7 +168,7 @@ func GetPreSignedUrl(s3Url *S3Url, minutes int64) (string, error) {
 		Region: aws.String(s3Url.Region),
 	})
 	if err != nil {
-		log.Printf("create session error.\n")
+		logger.Error("create session error", "err", err)
 		return "", err
 	}
 
@@ -186,7 +180,7 @@ func GetPreSignedUrl(s3Url *S3Url, minutes int64) (string, error) {
 
 	preSignedUrl, err := req.Presign(time.Duration(minutes) * time.Minute)
 	if err != nil {
-		log.Println("Failed to sign request", err)
+		logger.Error("failed to sign request", "err", err)
 		return "", err
 	}
 	return preSignedUrl, nil
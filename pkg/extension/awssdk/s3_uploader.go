@@ -1,13 +1,14 @@
 package awssdk
 
 import (
+	"context"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
-	"io"
-	"log"
 	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/retry"
+	"io"
 	"os"
 	"path/filepath"
 	"sync/atomic"
@@ -21,13 +22,13 @@ func UploadS3(bucketName string, s3Folder string, localFileName string,
 	// open file
 	file, err := os.Open(localFileName)
 	if err != nil {
-		log.Printf("file does not exist. (err:%v)", err)
+		logger.Error("file does not exist", "path", localFileName, "err", err)
 		return nil, err
 	}
 	defer func() {
 		if file != nil {
 			if err := file.Close(); err != nil {
-				log.Printf("file close err : %v", err)
+				logger.Warn("file close error", "path", localFileName, "err", err)
 			}
 		}
 	}()
@@ -35,7 +36,7 @@ func UploadS3(bucketName string, s3Folder string, localFileName string,
 	// get file information
 	fileInfo, err := file.Stat()
 	if err != nil {
-		log.Printf("can not get file info. (err:%v)", err)
+		logger.Error("can not get file info", "path", localFileName, "err", err)
 		return nil, err
 	}
 
@@ -51,10 +52,16 @@ func UploadS3(bucketName string, s3Folder string, localFileName string,
 	uploader.PartSize = 40 * 1024 * 1024
 
 	var output *s3manager.UploadOutput
-	for count := 0; count < common.DefaultMaxRetryCount; count++ {
+	err = retry.Do(context.Background(), retryPolicy("s3 upload"), func(ctx context.Context) error {
+
+		// 이전 시도에서 읽은 위치부터 올리지 않도록 처음으로 되돌림
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return retry.Permanent(err)
+		}
 
 		// upload file
-		output, err = uploader.Upload(
+		var err error
+		output, err = uploader.UploadWithContext(ctx,
 			&s3manager.UploadInput{
 				Body: &progressReader{
 					read:     0,
@@ -67,24 +74,21 @@ func UploadS3(bucketName string, s3Folder string, localFileName string,
 				Key:    aws.String(fmt.Sprintf("%v/%v", s3Folder, uploadFileName)),
 			},
 			func(uploader *s3manager.Uploader) {
-				log.Printf("upload : %v\n", uploader)
+				logger.Debug("upload", "part_size", uploader.PartSize, "concurrency", uploader.Concurrency)
 			})
-
-		if err != nil {
-			log.Printf("upload failed. (retry times:%v, err:%v)\n", count, err)
-			time.Sleep(1 * time.Second)
-		} else {
-			// call end callback
-			if endCallback != nil {
-				endCallback(output)
-			}
-			log.Printf("upload inforrmation : %v\n", output)
-			return output, nil
-		}
+		return err
+	})
+	if err != nil {
+		logger.Error("s3 upload failed", "bucket", bucketName, "path", localFileName, "err", err)
+		return nil, err
 	}
 
-	log.Printf("upload failed finally (err:%v)\n", err)
-	return nil, err
+	// call end callback
+	if endCallback != nil {
+		endCallback(output)
+	}
+	logger.Info("file uploaded", "location", output.Location, "upload_id", output.UploadID)
+	return output, nil
 }
 
 type progressReader struct {
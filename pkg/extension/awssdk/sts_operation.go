@@ -8,7 +8,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
-	"log"
 	"github.com/hwangtaeseung/neptune-core/pkg/common"
 	"strings"
 	"time"
@@ -17,11 +16,11 @@ import (
 func GetS3CredentialQueryString(region, serviceName string) (string, error) {
 
 	if headers, err := GetSignature(region, serviceName); err != nil {
-		log.Printf("get signature error (err:%v)\n", err)
+		logger.Error("get signature error", "err", err)
 		return "", err
 	} else {
 		contentSha256 := hex.EncodeToString(GetSha256("", []byte(headers.SecretAccessKey)))
-		log.Printf("* empty string sha256 : %v\n", contentSha256)
+		logger.Debug("empty string sha256", "sha256", contentSha256)
 		return common.QueryString(map[string]string{
 			"authorization": fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%v/%v/%v/%v/aws4_request, SignedHeaders=host;x-amz-date;x-amz-security-token;x-amz-content-sha256, Signature=%v",
 				headers.AccessKeyId, headers.DateStamp, region, serviceName, headers.Signature),
@@ -43,7 +42,7 @@ func GetSignature(region, serviceName string) (*CredentialHeaders, error) {
 		DurationSeconds: aws.Int64(int64(2 * 60 * 60)),
 	})
 	if err != nil {
-		log.Printf("get federation totken error. (err:%v)", err)
+		logger.Error("get federation token error", "err", err)
 		return nil, err
 	}
 
@@ -0,0 +1,491 @@
+package ffprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logger ffprobe package 로거 (logging.Configure("ffprobe", ...) 로 설정)
+var logger = logging.For("ffprobe")
+
+// DefaultBinaryPath PATH 에서 찾을 ffprobe 실행 파일
+const DefaultBinaryPath = "ffprobe"
+
+const (
+	CodecTypeVideo    = "video"
+	CodecTypeAudio    = "audio"
+	CodecTypeSubtitle = "subtitle"
+	CodecTypeData     = "data"
+)
+
+// DefaultProber Probe 에서 사용
+var DefaultProber = NewProber(DefaultBinaryPath)
+
+// Prober ffprobe 실행기
+type Prober struct {
+	// ffprobe 실행 파일 이름 혹은 경로
+	BinaryPath string
+
+	// ffprobe 에 추가로 전달할 인자 (ex: -analyzeduration, -probesize)
+	ExtraArgs []string
+}
+
+func NewProber(binaryPath string) *Prober {
+	return &Prober{BinaryPath: binaryPath}
+}
+
+// Probe DefaultProber 로 path (파일 경로 혹은 url) 의 format, stream, chapter 정보 조회
+func Probe(ctx context.Context, path string) (*MediaInfo, error) {
+	return DefaultProber.Probe(ctx, path)
+}
+
+// Probe path (파일 경로 혹은 url) 의 format, stream, chapter 정보 조회.
+// 실행 실패시 ExternalProgramExecutor 의 NeptuneError, 출력 파싱 실패시 common.ErrMediaProbe 반환
+func (p *Prober) Probe(ctx context.Context, path string) (*MediaInfo, error) {
+
+	inputArgs := append([]string{
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format", "-show_streams", "-show_chapters",
+	}, p.ExtraArgs...)
+
+	executor := common.NewExternalProgramExecutor(p.BinaryPath, inputArgs, []string{path})
+	output, err := executor.ExecuteContext(ctx)
+	if err != nil {
+		logger.Error("ffprobe error", "path", path, "err", err)
+		return nil, err
+	}
+
+	return Parse([]byte(output))
+}
+
+// Parse ffprobe JSON 출력 (-print_format json -show_format -show_streams -show_chapters) 변환
+func Parse(output []byte) (*MediaInfo, error) {
+
+	var raw probeOutput
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, common.ErrMediaProbe.Copy(fmt.Errorf("ffprobe output parse error : %w", err))
+	}
+	if raw.Format == nil {
+		return nil, common.ErrMediaProbe.Copy(fmt.Errorf("ffprobe output has no format"))
+	}
+
+	mediaInfo := &MediaInfo{
+		Format: raw.Format.convert(),
+	}
+	for _, stream := range raw.Streams {
+		mediaInfo.Streams = append(mediaInfo.Streams, stream.convert())
+	}
+	for _, chapter := range raw.Chapters {
+		mediaInfo.Chapters = append(mediaInfo.Chapters, chapter.convert())
+	}
+	return mediaInfo, nil
+}
+
+// MediaInfo ffprobe 결과
+type MediaInfo struct {
+	Format   *Format    `json:"format"`
+	Streams  []*Stream  `json:"streams"`
+	Chapters []*Chapter `json:"chapters,omitempty"`
+}
+
+// StreamsOf codecType (CodecTypeVideo, CodecTypeAudio, ...) 의 stream 목록
+func (m *MediaInfo) StreamsOf(codecType string) []*Stream {
+	var streams []*Stream
+	for _, stream := range m.Streams {
+		if stream.CodecType == codecType {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+// VideoStream 첫번째 video stream (attached picture 제외). 없으면 nil
+func (m *MediaInfo) VideoStream() *Stream {
+	for _, stream := range m.StreamsOf(CodecTypeVideo) {
+		if stream.Disposition["attached_pic"] == 0 {
+			return stream
+		}
+	}
+	return nil
+}
+
+// AudioStream 첫번째 audio stream. 없으면 nil
+func (m *MediaInfo) AudioStream() *Stream {
+	if streams := m.StreamsOf(CodecTypeAudio); len(streams) > 0 {
+		return streams[0]
+	}
+	return nil
+}
+
+// Format container 정보
+type Format struct {
+	FileName       string            `json:"file_name"`
+	FormatName     string            `json:"format_name"`
+	FormatLongName string            `json:"format_long_name"`
+	StreamCount    int               `json:"stream_count"`
+	StartTime      time.Duration     `json:"start_time"`
+	Duration       time.Duration     `json:"duration"`
+	Size           int64             `json:"size"`
+	BitRate        int64             `json:"bit_rate"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+// Stream video/audio/subtitle/data stream 정보
+type Stream struct {
+	Index         int               `json:"index"`
+	CodecType     string            `json:"codec_type"`
+	CodecName     string            `json:"codec_name"`
+	CodecLongName string            `json:"codec_long_name"`
+	CodecTag      string            `json:"codec_tag"`
+	Profile       string            `json:"profile,omitempty"`
+	Level         int               `json:"level,omitempty"`
+	StartTime     time.Duration     `json:"start_time"`
+	Duration      time.Duration     `json:"duration"`
+	BitRate       int64             `json:"bit_rate"`
+	FrameCount    int64             `json:"frame_count,omitempty"`
+	Language      string            `json:"language,omitempty"`
+	Disposition   map[string]int    `json:"disposition,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+
+	// video
+	Width              int     `json:"width,omitempty"`
+	Height             int     `json:"height,omitempty"`
+	PixelFormat        string  `json:"pixel_format,omitempty"`
+	FrameRate          float64 `json:"frame_rate,omitempty"`
+	AverageFrameRate   float64 `json:"average_frame_rate,omitempty"`
+	SampleAspectRatio  string  `json:"sample_aspect_ratio,omitempty"`
+	DisplayAspectRatio string  `json:"display_aspect_ratio,omitempty"`
+	FieldOrder         string  `json:"field_order,omitempty"`
+	BitsPerRawSample   int     `json:"bits_per_raw_sample,omitempty"`
+	Color              *Color  `json:"color,omitempty"`
+
+	// audio
+	SampleRate    int    `json:"sample_rate,omitempty"`
+	SampleFormat  string `json:"sample_format,omitempty"`
+	Channels      int    `json:"channels,omitempty"`
+	ChannelLayout string `json:"channel_layout,omitempty"`
+}
+
+// Rotation display matrix 혹은 rotate tag 의 회전 각도
+func (s *Stream) Rotation() int {
+	rotation, _ := strconv.Atoi(s.Tags["rotate"])
+	return rotation
+}
+
+// IsHDR PQ (HDR10, HDR10+, Dolby Vision) 혹은 HLG 여부
+func (s *Stream) IsHDR() bool {
+	return s.Color != nil && s.Color.HDRFormat != ""
+}
+
+const (
+	HDRFormatHDR10        = "HDR10"
+	HDRFormatHDR10Plus    = "HDR10+"
+	HDRFormatHLG          = "HLG"
+	HDRFormatDolbyVision  = "DolbyVision"
+	colorTransferPQ       = "smpte2084"
+	colorTransferHLG      = "arib-std-b67"
+	sideDataMastering     = "Mastering display metadata"
+	sideDataContentLight  = "Content light level metadata"
+	sideDataDolbyVision   = "DOVI configuration record"
+	sideDataHDR10Plus     = "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"
+	sideDataDisplayMatrix = "Display Matrix"
+)
+
+// Color video color 및 HDR 정보
+type Color struct {
+	Range      string `json:"range,omitempty"`
+	Space      string `json:"space,omitempty"`
+	Transfer   string `json:"transfer,omitempty"`
+	Primaries  string `json:"primaries,omitempty"`
+	ChromaSite string `json:"chroma_site,omitempty"`
+
+	// HDRFormatHDR10, HDRFormatHDR10Plus, HDRFormatHLG, HDRFormatDolbyVision (SDR 이면 빈 문자열)
+	HDRFormat string `json:"hdr_format,omitempty"`
+
+	MasteringDisplay *MasteringDisplay `json:"mastering_display,omitempty"`
+
+	// content light level (cd/m2)
+	MaxCLL  int `json:"max_cll,omitempty"`
+	MaxFALL int `json:"max_fall,omitempty"`
+
+	DolbyVision *DolbyVision `json:"dolby_vision,omitempty"`
+}
+
+// MasteringDisplay SMPTE ST 2086 mastering display 정보 (색좌표는 0 ~ 1, 휘도는 cd/m2)
+type MasteringDisplay struct {
+	RedX         float64 `json:"red_x"`
+	RedY         float64 `json:"red_y"`
+	GreenX       float64 `json:"green_x"`
+	GreenY       float64 `json:"green_y"`
+	BlueX        float64 `json:"blue_x"`
+	BlueY        float64 `json:"blue_y"`
+	WhitePointX  float64 `json:"white_point_x"`
+	WhitePointY  float64 `json:"white_point_y"`
+	MinLuminance float64 `json:"min_luminance"`
+	MaxLuminance float64 `json:"max_luminance"`
+}
+
+type DolbyVision struct {
+	Profile                  int `json:"profile"`
+	Level                    int `json:"level"`
+	BaseLayerCompatibilityId int `json:"bl_signal_compatibility_id"`
+}
+
+// Chapter chapter 정보
+type Chapter struct {
+	Id        int64             `json:"id"`
+	StartTime time.Duration     `json:"start_time"`
+	EndTime   time.Duration     `json:"end_time"`
+	Title     string            `json:"title,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// ffprobe JSON 출력. 숫자 값 대부분이 문자열로 출력된다
+type probeOutput struct {
+	Format   *probeFormat    `json:"format"`
+	Streams  []*probeStream  `json:"streams"`
+	Chapters []*probeChapter `json:"chapters"`
+}
+
+type probeFormat struct {
+	FileName       string            `json:"filename"`
+	StreamCount    int               `json:"nb_streams"`
+	FormatName     string            `json:"format_name"`
+	FormatLongName string            `json:"format_long_name"`
+	StartTime      string            `json:"start_time"`
+	Duration       string            `json:"duration"`
+	Size           string            `json:"size"`
+	BitRate        string            `json:"bit_rate"`
+	Tags           map[string]string `json:"tags"`
+}
+
+func (f *probeFormat) convert() *Format {
+	return &Format{
+		FileName:       f.FileName,
+		FormatName:     f.FormatName,
+		FormatLongName: f.FormatLongName,
+		StreamCount:    f.StreamCount,
+		StartTime:      parseSeconds(f.StartTime),
+		Duration:       parseSeconds(f.Duration),
+		Size:           parseInt(f.Size),
+		BitRate:        parseInt(f.BitRate),
+		Tags:           f.Tags,
+	}
+}
+
+type probeStream struct {
+	Index              int               `json:"index"`
+	CodecName          string            `json:"codec_name"`
+	CodecLongName      string            `json:"codec_long_name"`
+	CodecType          string            `json:"codec_type"`
+	CodecTagString     string            `json:"codec_tag_string"`
+	Profile            string            `json:"profile"`
+	Level              int               `json:"level"`
+	Width              int               `json:"width"`
+	Height             int               `json:"height"`
+	PixelFormat        string            `json:"pix_fmt"`
+	SampleAspectRatio  string            `json:"sample_aspect_ratio"`
+	DisplayAspectRatio string            `json:"display_aspect_ratio"`
+	FieldOrder         string            `json:"field_order"`
+	ColorRange         string            `json:"color_range"`
+	ColorSpace         string            `json:"color_space"`
+	ColorTransfer      string            `json:"color_transfer"`
+	ColorPrimaries     string            `json:"color_primaries"`
+	ChromaLocation     string            `json:"chroma_location"`
+	RFrameRate         string            `json:"r_frame_rate"`
+	AvgFrameRate       string            `json:"avg_frame_rate"`
+	SampleFormat       string            `json:"sample_fmt"`
+	SampleRate         string            `json:"sample_rate"`
+	Channels           int               `json:"channels"`
+	ChannelLayout      string            `json:"channel_layout"`
+	StartTime          string            `json:"start_time"`
+	Duration           string            `json:"duration"`
+	BitRate            string            `json:"bit_rate"`
+	BitsPerRawSample   string            `json:"bits_per_raw_sample"`
+	FrameCount         string            `json:"nb_frames"`
+	Disposition        map[string]int    `json:"disposition"`
+	Tags               map[string]string `json:"tags"`
+	SideDataList       []probeSideData   `json:"side_data_list"`
+}
+
+// probeSideData side data 종류별로 필드가 다르므로 값은 그대로 보관
+type probeSideData map[string]interface{}
+
+func (s probeSideData) string(key string) string {
+	switch value := s[key].(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return ""
+}
+
+func (s probeSideData) int(key string) int {
+	return int(parseRational(s.string(key)))
+}
+
+func (s *probeStream) convert() *Stream {
+
+	stream := &Stream{
+		Index:              s.Index,
+		CodecType:          s.CodecType,
+		CodecName:          s.CodecName,
+		CodecLongName:      s.CodecLongName,
+		CodecTag:           s.CodecTagString,
+		Profile:            s.Profile,
+		Level:              s.Level,
+		StartTime:          parseSeconds(s.StartTime),
+		Duration:           parseSeconds(s.Duration),
+		BitRate:            parseInt(s.BitRate),
+		FrameCount:         parseInt(s.FrameCount),
+		Language:           s.Tags["language"],
+		Disposition:        s.Disposition,
+		Tags:               s.Tags,
+		Width:              s.Width,
+		Height:             s.Height,
+		PixelFormat:        s.PixelFormat,
+		FrameRate:          parseRational(s.RFrameRate),
+		AverageFrameRate:   parseRational(s.AvgFrameRate),
+		SampleAspectRatio:  s.SampleAspectRatio,
+		DisplayAspectRatio: s.DisplayAspectRatio,
+		FieldOrder:         s.FieldOrder,
+		BitsPerRawSample:   int(parseInt(s.BitsPerRawSample)),
+		SampleRate:         int(parseInt(s.SampleRate)),
+		SampleFormat:       s.SampleFormat,
+		Channels:           s.Channels,
+		ChannelLayout:      s.ChannelLayout,
+	}
+
+	// duration 이 없는 stream (mkv 등) 은 DURATION tag 사용 (HH:MM:SS.nnnnnnnnn)
+	if stream.Duration == 0 {
+		if duration, ok := s.Tags["DURATION"]; ok {
+			stream.Duration = time.Duration(common.DurationToSecond(duration) * float64(time.Second))
+		}
+	}
+
+	if s.CodecType == CodecTypeVideo {
+		stream.Color = s.color()
+		for _, sideData := range s.SideDataList {
+			if sideData.string("side_data_type") == sideDataDisplayMatrix && sideData.string("rotation") != "" {
+				if stream.Tags == nil {
+					stream.Tags = map[string]string{}
+				}
+				if _, ok := stream.Tags["rotate"]; !ok {
+					stream.Tags["rotate"] = strconv.Itoa(-sideData.int("rotation"))
+				}
+			}
+		}
+	}
+	return stream
+}
+
+func (s *probeStream) color() *Color {
+
+	color := &Color{
+		Range:      s.ColorRange,
+		Space:      s.ColorSpace,
+		Transfer:   s.ColorTransfer,
+		Primaries:  s.ColorPrimaries,
+		ChromaSite: s.ChromaLocation,
+	}
+
+	switch s.ColorTransfer {
+	case colorTransferPQ:
+		color.HDRFormat = HDRFormatHDR10
+	case colorTransferHLG:
+		color.HDRFormat = HDRFormatHLG
+	}
+
+	for _, sideData := range s.SideDataList {
+		switch sideData.string("side_data_type") {
+		case sideDataMastering:
+			color.MasteringDisplay = &MasteringDisplay{
+				RedX:         parseRational(sideData.string("red_x")),
+				RedY:         parseRational(sideData.string("red_y")),
+				GreenX:       parseRational(sideData.string("green_x")),
+				GreenY:       parseRational(sideData.string("green_y")),
+				BlueX:        parseRational(sideData.string("blue_x")),
+				BlueY:        parseRational(sideData.string("blue_y")),
+				WhitePointX:  parseRational(sideData.string("white_point_x")),
+				WhitePointY:  parseRational(sideData.string("white_point_y")),
+				MinLuminance: parseRational(sideData.string("min_luminance")),
+				MaxLuminance: parseRational(sideData.string("max_luminance")),
+			}
+		case sideDataContentLight:
+			color.MaxCLL = sideData.int("max_content")
+			color.MaxFALL = sideData.int("max_average")
+		case sideDataHDR10Plus:
+			if color.HDRFormat == HDRFormatHDR10 {
+				color.HDRFormat = HDRFormatHDR10Plus
+			}
+		case sideDataDolbyVision:
+			color.HDRFormat = HDRFormatDolbyVision
+			color.DolbyVision = &DolbyVision{
+				Profile:                  sideData.int("dv_profile"),
+				Level:                    sideData.int("dv_level"),
+				BaseLayerCompatibilityId: sideData.int("dv_bl_signal_compatibility_id"),
+			}
+		}
+	}
+
+	if *color == (Color{}) {
+		return nil
+	}
+	return color
+}
+
+type probeChapter struct {
+	Id        int64             `json:"id"`
+	StartTime string            `json:"start_time"`
+	EndTime   string            `json:"end_time"`
+	Tags      map[string]string `json:"tags"`
+}
+
+func (c *probeChapter) convert() *Chapter {
+	return &Chapter{
+		Id:        c.Id,
+		StartTime: parseSeconds(c.StartTime),
+		EndTime:   parseSeconds(c.EndTime),
+		Title:     c.Tags["title"],
+		Tags:      c.Tags,
+	}
+}
+
+// parseSeconds "12.345000" -> time.Duration ("N/A" 등은 0)
+func parseSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func parseInt(value string) int64 {
+	number, _ := strconv.ParseInt(value, 10, 64)
+	return number
+}
+
+// parseRational "30000/1001" 혹은 "10000000/10000" 같은 분수 혹은 숫자 변환 (분모가 0 이면 0)
+func parseRational(value string) float64 {
+	numerator, denominator := value, "1"
+	if index := strings.Index(value, "/"); index >= 0 {
+		numerator, denominator = value[:index], value[index+1:]
+	}
+	n, err := strconv.ParseFloat(numerator, 64)
+	if err != nil {
+		return 0
+	}
+	d, err := strconv.ParseFloat(denominator, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
@@ -0,0 +1,98 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeProber testdata/<입력 파일 이름>.json 을 출력하는 ffprobe 대신 사용
+var fakeProber = NewProber("testdata/fake-ffprobe")
+
+func TestProbeHDR10(t *testing.T) {
+
+	info, err := fakeProber.Probe(context.Background(), "hdr10.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Format.Duration != 10016*time.Millisecond {
+		t.Errorf("Format.Duration = %v, want 10.016s", info.Format.Duration)
+	}
+
+	video := info.VideoStream()
+	if video == nil {
+		t.Fatal("VideoStream() = nil")
+	}
+	if video.Width != 3840 || video.Height != 2160 {
+		t.Errorf("resolution = %vx%v, want 3840x2160", video.Width, video.Height)
+	}
+	if math.Abs(video.FrameRate-24000.0/1001.0) > 1e-9 {
+		t.Errorf("FrameRate = %v, want 23.976", video.FrameRate)
+	}
+	if !video.IsHDR() || video.Color.HDRFormat != HDRFormatHDR10 {
+		t.Fatalf("Color = %+v, want %v", video.Color, HDRFormatHDR10)
+	}
+	if video.Color.MaxCLL != 1000 || video.Color.MaxFALL != 400 {
+		t.Errorf("MaxCLL, MaxFALL = %v, %v, want 1000, 400", video.Color.MaxCLL, video.Color.MaxFALL)
+	}
+	if mastering := video.Color.MasteringDisplay; mastering == nil || mastering.MaxLuminance != 1000 || mastering.MinLuminance != 0.005 {
+		t.Errorf("MasteringDisplay = %+v, want luminance 0.005 ~ 1000", mastering)
+	}
+
+	audio := info.AudioStream()
+	if audio == nil {
+		t.Fatal("AudioStream() = nil")
+	}
+	if audio.Channels != 6 || audio.ChannelLayout != "5.1(side)" {
+		t.Errorf("audio channels = %v (%v), want 6 (5.1(side))", audio.Channels, audio.ChannelLayout)
+	}
+
+	if streams := info.StreamsOf(CodecTypeSubtitle); len(streams) != 1 || streams[0].IsHDR() {
+		t.Errorf("StreamsOf(subtitle) = %v, want 1 stream", streams)
+	}
+}
+
+func TestProbeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		prober *Prober
+		path   string
+		want   *common.NeptuneError
+	}{
+		{name: "missing binary", prober: NewProber("testdata/no-such-ffprobe"), path: "hdr10.mkv", want: common.ErrExecNotFound},
+		{name: "missing input", prober: fakeProber, path: "missing.mkv", want: common.ErrExecNonZeroExit},
+		{name: "malformed json", prober: fakeProber, path: "malformed.mkv", want: common.ErrMediaProbe},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, err := test.prober.Probe(context.Background(), test.path)
+			if info != nil || !errors.Is(err, test.want) {
+				t.Errorf("Probe() = %v, %v, want code %v", info, err, test.want.Code())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{name: "empty", output: ""},
+		{name: "not json", output: "Invalid data found when processing input"},
+		{name: "no format", output: `{"streams": []}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse([]byte(test.output)); !errors.Is(err, common.ErrMediaProbe) {
+				t.Errorf("Parse() error = %v, want ErrMediaProbe", err)
+			}
+		})
+	}
+}
@@ -1,17 +1,26 @@
 package dbwrapper
 
 import (
+	"context"
 	"database/sql"
-	"log"
-	"math"
 	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
+	"github.com/hwangtaeseung/neptune-core/pkg/retry"
+	"math"
+	"time"
 )
 
+// logger rdb package 로거 (logging.Configure("rdb", ...) 로 설정)
+var logger = logging.For("rdb")
+
 type HevcDB struct {
 	vendor  string
 	dbInfo  string
 	maxConn int
 	dbOff   bool
+
+	// ExecSQLWithTx 재시도 정책 (nil 이면 기존과 같이 1초 간격으로 재시도하는 retry.ConstantPolicy)
+	RetryPolicy *retry.Policy
 }
 
 func NewDB(vendor string, connectString string, maxConn int) *HevcDB {
@@ -35,7 +44,7 @@ func (d *HevcDB) GetDBInfo() string {
 func (d *HevcDB) open() (*sql.DB, error) {
 	db, err := sql.Open(d.vendor, d.dbInfo)
 	if err != nil {
-		log.Printf("db open error")
+		logger.Error("db open error", "vendor", d.vendor, "err", err)
 		return nil, err
 	}
 	// set max num of connection
@@ -43,51 +52,94 @@ func (d *HevcDB) open() (*sql.DB, error) {
 	return db, nil
 }
 
+// Ping db 접속 가능 여부 확인 (db off 인 경우 항상 성공)
+func (d *HevcDB) Ping(ctx context.Context) error {
+	if d.dbOff {
+		return nil
+	}
+	db, err := d.open()
+	if err != nil {
+		return err
+	}
+	defer func(db *sql.DB) {
+		if err := db.Close(); err != nil {
+			logger.Warn("db close error", "err", err)
+		}
+	}(db)
+	return db.PingContext(ctx)
+}
+
+// HealthCheck common.HealthRegistry 에 등록할 db 점검 함수
+func (d *HevcDB) HealthCheck() common.HealthCheckFunc {
+	return d.Ping
+}
+
 func (d *HevcDB) ExecSQLWithTx(callback func(tx *sql.Tx) (interface{}, error)) (interface{}, error) {
+	return d.ExecSQLWithTxContext(context.Background(), callback)
+}
 
-	return common.RetryWrapper(func() (interface{}, error) {
-		if d.dbOff {
-			log.Println("db off!!!")
-			return []interface{}{}, nil
-		}
+// ExecSQLWithTxContext callback 을 transaction 안에서 실행하고 commit. 실패하면 RetryPolicy 에 따라 재시도
+// (callback 이 retry.Permanent 로 감싼 에러를 반환하면 재시도하지 않음)
+func (d *HevcDB) ExecSQLWithTxContext(ctx context.Context, callback func(tx *sql.Tx) (interface{}, error)) (interface{}, error) {
+
+	if d.dbOff {
+		logger.Debug("db off. skip transaction")
+		return []interface{}{}, nil
+	}
+
+	policy := retry.ConstantPolicy(time.Second)
+	if d.RetryPolicy != nil {
+		copied := *d.RetryPolicy
+		policy = &copied
+	}
+	if policy.OnAttempt == nil {
+		policy.OnAttempt = retry.LogAttempt("sql transaction")
+	}
+
+	var result interface{}
+	err := retry.Do(ctx, policy, func(ctx context.Context) error {
 
 		// open db
 		db, err := d.open()
 		if err != nil {
-			log.Printf("db open error : %v\n", err)
-			return nil, err
+			return err
 		}
 		defer func(db *sql.DB) {
 			if err := db.Close(); err != nil {
-				log.Printf("db close error : %v\n", err)
+				logger.Warn("db close error", "err", err)
 			}
 		}(db)
 
 		// begin tx
-		tx, err := db.Begin()
+		tx, err := db.BeginTx(ctx, nil)
 		if err != nil {
-			log.Printf("transaction creation error : %+v", err)
-			return nil, err
+			logger.Error("transaction creation error", "err", err)
+			return err
 		}
 		defer func(tx *sql.Tx) {
 			if err := tx.Rollback(); err != nil {
-				//log.Printf("rollback error! (err:%+v)", err)
+				//logger.Debug("rollback error", "err", err)
 			}
 		}(tx)
 
 		// execute sql callback
 		if ret, err := callback(tx); err != nil {
-			log.Printf("sql callback error : %+v", err)
-			return nil, err
+			logger.Error("sql callback error", "err", err)
+			return err
 		} else {
 			if err := tx.Commit(); err != nil {
-				log.Printf("sql commit error : %v\n", err)
-				return nil, err
+				logger.Error("sql commit error", "err", err)
+				return err
 			}
 			// commit
-			return ret, nil
+			result = ret
+			return nil
 		}
-	},  common.DefaultMaxRetryCount)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 type HevcSql struct {
@@ -107,7 +159,7 @@ func (d *HevcDB) ExecuteSQLs(sqlStatements []*HevcSql) ([]interface{}, error) {
 		}
 		return results, nil
 	}); err != nil {
-		log.Printf("exec sql error : %v\n", err)
+		logger.Error("exec sql error", "err", err)
 		return nil, err
 	} else {
 		return results.([]interface{}), nil
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// ParseLevel debug, info, warn (warning), error (대소문자 무시)
+func ParseLevel(level string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level : %v", level)
+	}
+}
+
+// Logger 구조화 로거. keyValues 는 key, value 를 번갈아 나열한다 (ex: logger.Info("job started", "job_id", id))
+type Logger interface {
+	Debug(message string, keyValues ...interface{})
+	Info(message string, keyValues ...interface{})
+	Warn(message string, keyValues ...interface{})
+	Error(message string, keyValues ...interface{})
+
+	// With keyValues 를 모든 로그에 추가한 Logger
+	With(keyValues ...interface{}) Logger
+
+	Enabled(level Level) bool
+}
+
+// Nop 아무것도 기록하지 않는 Logger (테스트용)
+func Nop() Logger {
+	return nopLogger{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (n nopLogger) With(...interface{}) Logger { return n }
+func (nopLogger) Enabled(Level) bool           { return false }
@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"io"
+	"log"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config package 별 로그 설정
+type Config struct {
+	Level Level
+
+	// nil 이면 표준 log 의 output (log.Writer())
+	Output io.Writer
+
+	// FormatText (기본값) 혹은 FormatJSON (한 줄에 하나의 JSON)
+	Format string
+}
+
+// NewLogger config 로 Logger 생성. FormatJSON 은 log/slog 의 JSON handler 를 사용한다
+func NewLogger(config *Config) Logger {
+	if config == nil {
+		config = &Config{}
+	}
+	output := config.Output
+	if output == nil {
+		output = log.Writer()
+	}
+	if config.Format == FormatJSON {
+		return NewSlogLogger(slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{
+			Level: toSlogLevel(config.Level),
+		})))
+	}
+	return NewStdLogger(log.New(output, "", log.LstdFlags), config.Level)
+}
+
+var registry = struct {
+	sync.RWMutex
+	loggers map[string]Logger
+
+	// 설정이 바뀔 때마다 증가 (packageLogger 의 cache 무효화)
+	generation int64
+}{loggers: map[string]Logger{}}
+
+// SetLogger pkg (websock, grpcwrapper, awssdk, rdb, common ...) 가 사용할 Logger 지정.
+// pkg 가 빈 문자열이면 따로 지정하지 않은 모든 package 의 기본값, logger 가 nil 이면 지정 해제
+func SetLogger(pkg string, logger Logger) {
+	registry.Lock()
+	defer registry.Unlock()
+	if logger == nil {
+		delete(registry.loggers, pkg)
+	} else {
+		registry.loggers[pkg] = logger
+	}
+	atomic.AddInt64(&registry.generation, 1)
+}
+
+// Configure SetLogger(pkg, NewLogger(config))
+func Configure(pkg string, config *Config) {
+	SetLogger(pkg, NewLogger(config))
+}
+
+func lookup(pkg string) Logger {
+	registry.RLock()
+	defer registry.RUnlock()
+	if logger, ok := registry.loggers[pkg]; ok {
+		return logger
+	}
+	if logger, ok := registry.loggers[""]; ok {
+		return logger
+	}
+	return defaultLogger
+}
+
+// defaultLogger 아무것도 설정하지 않았을 때 표준 log 로 info 이상 기록
+var defaultLogger = NewStdLogger(nil, LevelInfo)
+
+// For pkg 의 Logger. package 변수로 만들어 두어도 이후의 SetLogger, Configure 설정을 따르며 모든 로그에 package 를 추가한다
+func For(pkg string) Logger {
+	return &packageLogger{pkg: pkg}
+}
+
+type packageLogger struct {
+	pkg    string
+	fields []interface{}
+	cache  atomic.Value
+}
+
+type cachedLogger struct {
+	generation int64
+	logger     Logger
+}
+
+func (p *packageLogger) resolve() Logger {
+	generation := atomic.LoadInt64(&registry.generation)
+	if cached, ok := p.cache.Load().(*cachedLogger); ok && cached.generation == generation {
+		return cached.logger
+	}
+	logger := lookup(p.pkg).With(append([]interface{}{"package", p.pkg}, p.fields...)...)
+	p.cache.Store(&cachedLogger{generation: generation, logger: logger})
+	return logger
+}
+
+func (p *packageLogger) Debug(message string, keyValues ...interface{}) {
+	p.resolve().Debug(message, keyValues...)
+}
+
+func (p *packageLogger) Info(message string, keyValues ...interface{}) {
+	p.resolve().Info(message, keyValues...)
+}
+
+func (p *packageLogger) Warn(message string, keyValues ...interface{}) {
+	p.resolve().Warn(message, keyValues...)
+}
+
+func (p *packageLogger) Error(message string, keyValues ...interface{}) {
+	p.resolve().Error(message, keyValues...)
+}
+
+func (p *packageLogger) With(keyValues ...interface{}) Logger {
+	return &packageLogger{
+		pkg:    p.pkg,
+		fields: append(append([]interface{}(nil), p.fields...), keyValues...),
+	}
+}
+
+func (p *packageLogger) Enabled(level Level) bool {
+	return p.resolve().Enabled(level)
+}
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogLogger log/slog 로 기록
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger logger 가 nil 이면 slog.Default()
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogLogger{logger: logger}
+}
+
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func (s *slogLogger) Debug(message string, keyValues ...interface{}) {
+	s.logger.Debug(message, keyValues...)
+}
+
+func (s *slogLogger) Info(message string, keyValues ...interface{}) {
+	s.logger.Info(message, keyValues...)
+}
+
+func (s *slogLogger) Warn(message string, keyValues ...interface{}) {
+	s.logger.Warn(message, keyValues...)
+}
+
+func (s *slogLogger) Error(message string, keyValues ...interface{}) {
+	s.logger.Error(message, keyValues...)
+}
+
+func (s *slogLogger) With(keyValues ...interface{}) Logger {
+	return &slogLogger{logger: s.logger.With(keyValues...)}
+}
+
+func (s *slogLogger) Enabled(level Level) bool {
+	return s.logger.Enabled(context.Background(), toSlogLevel(level))
+}
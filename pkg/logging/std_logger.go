@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// stdLogger 표준 log.Logger 로 "LEVEL message key=value ..." 형식의 text 를 기록
+type stdLogger struct {
+	logger *log.Logger
+	level  Level
+	fields []interface{}
+}
+
+// NewStdLogger logger 가 nil 이면 표준 log 의 기본 logger (log.SetOutput, log.SetFlags 설정을 따름)
+func NewStdLogger(logger *log.Logger, level Level) Logger {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &stdLogger{logger: logger, level: level}
+}
+
+func (s *stdLogger) Debug(message string, keyValues ...interface{}) {
+	s.write(LevelDebug, message, keyValues)
+}
+
+func (s *stdLogger) Info(message string, keyValues ...interface{}) {
+	s.write(LevelInfo, message, keyValues)
+}
+
+func (s *stdLogger) Warn(message string, keyValues ...interface{}) {
+	s.write(LevelWarn, message, keyValues)
+}
+
+func (s *stdLogger) Error(message string, keyValues ...interface{}) {
+	s.write(LevelError, message, keyValues)
+}
+
+func (s *stdLogger) With(keyValues ...interface{}) Logger {
+	return &stdLogger{
+		logger: s.logger,
+		level:  s.level,
+		fields: append(append([]interface{}(nil), s.fields...), keyValues...),
+	}
+}
+
+func (s *stdLogger) Enabled(level Level) bool {
+	return level >= s.level
+}
+
+func (s *stdLogger) write(level Level, message string, keyValues []interface{}) {
+	if !s.Enabled(level) {
+		return
+	}
+	var builder strings.Builder
+	builder.WriteString(level.String())
+	builder.WriteByte(' ')
+	builder.WriteString(message)
+	appendKeyValues(&builder, s.fields)
+	appendKeyValues(&builder, keyValues)
+	// stdLogger.write, Debug (Info ...) 제외
+	_ = s.logger.Output(3, builder.String())
+}
+
+func appendKeyValues(builder *strings.Builder, keyValues []interface{}) {
+	for index := 0; index < len(keyValues); index += 2 {
+		builder.WriteByte(' ')
+		if index+1 == len(keyValues) {
+			// 짝이 없는 값
+			builder.WriteString("!BADKEY=")
+			builder.WriteString(quote(fmt.Sprint(keyValues[index])))
+			break
+		}
+		builder.WriteString(fmt.Sprint(keyValues[index]))
+		builder.WriteByte('=')
+		builder.WriteString(quote(fmt.Sprint(keyValues[index+1])))
+	}
+}
+
+// quote 공백, 따옴표, = 가 있으면 따옴표로 감싼다
+func quote(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+		return strconv.Quote(value)
+	}
+	return value
+}
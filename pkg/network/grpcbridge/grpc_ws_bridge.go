@@ -0,0 +1,244 @@
+package grpcbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
+	"github.com/hwangtaeseung/neptune-core/pkg/network/grpcwrapper"
+	"github.com/hwangtaeseung/neptune-core/pkg/network/websock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"sync"
+	"time"
+)
+
+// logger grpcbridge package 로거 (logging.Configure("grpcbridge", ...) 로 설정)
+var logger = logging.For("grpcbridge")
+
+// BridgeMessage websocket 으로 주고받는 JSON frame
+type BridgeMessage struct {
+	ProtocolId string          `json:"protocol_id"`
+	RequestId  string          `json:"request_id,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Error      *BridgeError    `json:"error,omitempty"`
+}
+
+type BridgeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// server 가 NeptuneError 를 반환한 경우 (grpcwrapper.ErrorUnaryInterceptor)
+	Detail *common.NeptuneError `json:"detail,omitempty"`
+}
+
+// StreamSubscription server-streaming rpc 구독 정보
+type StreamSubscription struct {
+	// websocket frame 의 protocol_id
+	ProtocolId string
+
+	// grpc method full name (ex: /job.JobService/WatchProgress)
+	Method string
+
+	Request     proto.Message
+	NewResponse func() proto.Message
+}
+
+// UnaryRoute websocket 요청을 전달할 unary rpc 정보
+type UnaryRoute struct {
+	Method      string
+	NewRequest  func() proto.Message
+	NewResponse func() proto.Message
+}
+
+// Bridge grpc 서비스를 websocket (WSServer, WSSession) 으로 중계
+type Bridge struct {
+	client  *grpcwrapper.GrpcClient
+	target  string
+	timeout time.Duration
+
+	mutex  sync.RWMutex
+	routes map[string]*UnaryRoute
+}
+
+// NewBridge timeout 은 unary 요청별 제한 시간
+func NewBridge(client *grpcwrapper.GrpcClient, target string, timeout time.Duration) *Bridge {
+	return &Bridge{
+		client:  client,
+		target:  target,
+		timeout: timeout,
+		routes:  map[string]*UnaryRoute{},
+	}
+}
+
+// RelayToServer stream 의 모든 메시지를 server 의 모든 session 에 전송. stream 이 끝나거나 ctx 가 취소되거나 server 가 종료될 때까지 block
+func (b *Bridge) RelayToServer(ctx context.Context, server *websock.WSServer, subscription *StreamSubscription) error {
+	return b.relay(ctx, subscription, func(frame []byte) bool {
+		return server.TryBroadcast(&websock.Message{MsgType: websocket.TextMessage, Message: frame})
+	})
+}
+
+// RelayToSession stream 의 모든 메시지를 session 에 전송. session 이 종료되면 구독도 종료
+func (b *Bridge) RelayToSession(ctx context.Context, session *websock.WSSession, subscription *StreamSubscription) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-session.Context().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return b.relay(ctx, subscription, func(frame []byte) bool {
+		return session.TrySend(&websock.Message{MsgType: websocket.TextMessage, Message: frame})
+	})
+}
+
+// relay sendFrame 이 false 를 반환하면 (전송 대상 종료) 구독을 종료하고 nil 반환
+func (b *Bridge) relay(ctx context.Context, subscription *StreamSubscription, sendFrame func([]byte) bool) error {
+
+	conn, err := b.client.Conn(ctx, b.target)
+	if err != nil {
+		logger.Error("bridge connect fail", "target", b.target, "err", err)
+		return err
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, subscription.Method)
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(subscription.Request); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	logger.Info("bridge stream relay started", "method", subscription.Method)
+	for {
+		response := subscription.NewResponse()
+		if err := stream.RecvMsg(response); err != nil {
+			if err == io.EOF {
+				logger.Info("bridge stream relay finished", "method", subscription.Method)
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.Error("bridge stream relay error", "method", subscription.Method, "err", err)
+			return err
+		}
+
+		frame, err := encodeFrame(&BridgeMessage{ProtocolId: subscription.ProtocolId}, response)
+		if err != nil {
+			logger.Warn("bridge message encoding error", "method", subscription.Method, "err", err)
+			continue
+		}
+		if !sendFrame(frame) {
+			logger.Info("bridge stream relay target closed", "method", subscription.Method)
+			return nil
+		}
+	}
+}
+
+// AddUnaryRoute protocolId 로 들어온 websocket 요청을 route 의 unary rpc 로 전달
+func (b *Bridge) AddUnaryRoute(protocolId string, route *UnaryRoute) *Bridge {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.routes[protocolId] = route
+	return b
+}
+
+// TextMessageHandler websock.NewWSHandler 의 text handler 로 사용. 응답은 요청한 session 으로 전송
+func (b *Bridge) TextMessageHandler() websock.WSMessageHandler {
+	return func(session *websock.WSSession, message []byte) {
+		go b.handleRequest(session, message)
+	}
+}
+
+func (b *Bridge) handleRequest(session *websock.WSSession, message []byte) {
+
+	var request BridgeMessage
+	if err := json.Unmarshal(message, &request); err != nil {
+		b.sendError(session, &request, "InvalidArgument", fmt.Sprintf("invalid bridge message : %v", err))
+		return
+	}
+
+	b.mutex.RLock()
+	route, ok := b.routes[request.ProtocolId]
+	b.mutex.RUnlock()
+	if !ok {
+		b.sendError(session, &request, "NotFound", fmt.Sprintf("unknown protocol id : %v", request.ProtocolId))
+		return
+	}
+
+	grpcRequest := route.NewRequest()
+	if len(request.Data) > 0 {
+		if err := protojson.Unmarshal(request.Data, grpcRequest); err != nil {
+			b.sendError(session, &request, "InvalidArgument", fmt.Sprintf("invalid request data : %v", err))
+			return
+		}
+	}
+
+	ctx := session.Context()
+	if ctx.Err() != nil {
+		return
+	}
+	response, err := b.client.Request(ctx, b.target, b.timeout, nil,
+		func(conn *grpc.ClientConn, ctx context.Context) (interface{}, error) {
+			grpcResponse := route.NewResponse()
+			if err := conn.Invoke(ctx, route.Method, grpcRequest, grpcResponse); err != nil {
+				return nil, err
+			}
+			return grpcResponse, nil
+		})
+	if err != nil {
+		grpcStatus := status.Convert(err)
+		bridgeError := &BridgeError{Code: grpcStatus.Code().String(), Message: grpcStatus.Message()}
+		if detail := grpcwrapper.FromGrpcStatus(err); detail.Code() != common.ErrUnknownCode {
+			bridgeError.Detail = detail
+		}
+		b.sendBridgeError(session, &request, bridgeError)
+		return
+	}
+
+	frame, err := encodeFrame(&BridgeMessage{ProtocolId: request.ProtocolId, RequestId: request.RequestId},
+		response.(proto.Message))
+	if err != nil {
+		b.sendError(session, &request, "Internal", err.Error())
+		return
+	}
+	session.Send(websocket.TextMessage, frame)
+}
+
+func (b *Bridge) sendError(session *websock.WSSession, request *BridgeMessage, code string, message string) {
+	b.sendBridgeError(session, request, &BridgeError{Code: code, Message: message})
+}
+
+func (b *Bridge) sendBridgeError(session *websock.WSSession, request *BridgeMessage, bridgeError *BridgeError) {
+	logger.Warn("bridge request error", "protocol_id", request.ProtocolId, "code", bridgeError.Code, "message", bridgeError.Message)
+	frame, err := json.Marshal(&BridgeMessage{
+		ProtocolId: request.ProtocolId,
+		RequestId:  request.RequestId,
+		Error:      bridgeError,
+	})
+	if err == nil {
+		session.Send(websocket.TextMessage, frame)
+	}
+}
+
+func encodeFrame(frame *BridgeMessage, message proto.Message) ([]byte, error) {
+	data, err := protojson.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	frame.Data = data
+	return json.Marshal(frame)
+}
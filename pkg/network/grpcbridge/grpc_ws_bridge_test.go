@@ -0,0 +1,283 @@
+package grpcbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/network/grpcwrapper"
+	"github.com/hwangtaeseung/neptune-core/pkg/network/websock"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	checkProtocol = "health.check"
+	watchProtocol = "health.watch"
+)
+
+// invalidService 이 service 이름의 Check 는 NeptuneError (ErrConfigInvalid) 로 실패한다
+const invalidService = "invalid"
+
+type bridgeFixture struct {
+	grpcServer *grpcwrapper.GrpcServer
+	bridge     *Bridge
+	wsServer   *websock.WSServer
+	httpServer *httptest.Server
+	sessions   chan *websock.WSSession
+}
+
+// newBridgeFixture grpc health service 를 backend 로 하는 bridge 와 websocket 서버
+func newBridgeFixture(t *testing.T) *bridgeFixture {
+	t.Helper()
+
+	grpcServer, err := grpcwrapper.NewGrpcServer("127.0.0.1:0", nil, grpcwrapper.WithUnaryInterceptors(
+		grpcwrapper.ErrorUnaryInterceptor(),
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if request, ok := req.(*healthpb.HealthCheckRequest); ok && request.Service == invalidService {
+				return nil, common.ErrConfigInvalid.Copy(errors.New("service name is not allowed"))
+			}
+			return handler(ctx, req)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = grpcServer.Run()
+	}()
+
+	client := grpcwrapper.NewGrpcClient()
+	bridge := NewBridge(client, grpcServer.Addr().String(), 5*time.Second).
+		AddUnaryRoute(checkProtocol, &UnaryRoute{
+			Method:      "/grpc.health.v1.Health/Check",
+			NewRequest:  func() proto.Message { return &healthpb.HealthCheckRequest{} },
+			NewResponse: func() proto.Message { return &healthpb.HealthCheckResponse{} },
+		})
+
+	fixture := &bridgeFixture{
+		grpcServer: grpcServer,
+		bridge:     bridge,
+		wsServer:   websock.NewWSServer("", websock.NewWSHandler(bridge.TextMessageHandler(), nil), nil),
+		sessions:   make(chan *websock.WSSession, 10),
+	}
+	fixture.wsServer.OnConnect = func(session *websock.WSSession) {
+		fixture.sessions <- session
+	}
+	fixture.wsServer.RunSessions()
+	fixture.httpServer = httptest.NewServer(fixture.wsServer.Handler())
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = fixture.wsServer.Shutdown(ctx)
+		fixture.httpServer.Close()
+		_ = client.Close()
+		_ = grpcServer.Shutdown(ctx)
+	})
+	return fixture
+}
+
+func (f *bridgeFixture) dial(t *testing.T) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(f.httpServer.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func readBridgeMessage(t *testing.T, conn *websocket.Conn) *BridgeMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	_, frame, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := &BridgeMessage{}
+	if err := json.Unmarshal(frame, message); err != nil {
+		t.Fatalf("invalid frame %q : %v", frame, err)
+	}
+	return message
+}
+
+func servingStatus(t *testing.T, message *BridgeMessage) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	response := &healthpb.HealthCheckResponse{}
+	if err := protojson.Unmarshal(message.Data, response); err != nil {
+		t.Fatalf("invalid data %q : %v", message.Data, err)
+	}
+	return response.Status
+}
+
+func TestBridgeUnaryRoute(t *testing.T) {
+
+	fixture := newBridgeFixture(t)
+	conn := fixture.dial(t)
+
+	tests := []struct {
+		name        string
+		request     string
+		protocolId  string
+		want        healthpb.HealthCheckResponse_ServingStatus
+		code        string
+		message     string
+		detailCode  int
+		hasResponse bool
+	}{
+		{
+			name: "routed", request: `{"protocol_id":"health.check","request_id":"1","data":{}}`,
+			protocolId: checkProtocol, want: healthpb.HealthCheckResponse_SERVING, hasResponse: true,
+		},
+		{
+			name: "without data", request: `{"protocol_id":"health.check","request_id":"2"}`,
+			protocolId: checkProtocol, want: healthpb.HealthCheckResponse_SERVING, hasResponse: true,
+		},
+		{
+			// grpc status 에러는 code 와 message 만 전달한다
+			name: "grpc error", request: `{"protocol_id":"health.check","request_id":"3","data":{"service":"cache"}}`,
+			protocolId: checkProtocol, code: "NotFound", message: "unknown service",
+		},
+		{
+			// NeptuneError 는 detail 로 복원해서 전달한다
+			name: "neptune error", request: `{"protocol_id":"health.check","request_id":"4","data":{"service":"invalid"}}`,
+			protocolId: checkProtocol, code: "InvalidArgument", message: "invalid configuration", detailCode: common.ErrConfigInvalid.Code(),
+		},
+		{
+			name: "unknown protocol", request: `{"protocol_id":"health.unknown","request_id":"5"}`,
+			protocolId: "health.unknown", code: "NotFound", message: "unknown protocol id",
+		},
+		{
+			name: "invalid data", request: `{"protocol_id":"health.check","request_id":"6","data":{"service":1}}`,
+			protocolId: checkProtocol, code: "InvalidArgument", message: "invalid request data",
+		},
+		{
+			name: "invalid message", request: `not json`,
+			code: "InvalidArgument", message: "invalid bridge message",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(test.request)); err != nil {
+				t.Fatal(err)
+			}
+			message := readBridgeMessage(t, conn)
+
+			if message.ProtocolId != test.protocolId {
+				t.Errorf("protocol_id = %q, want %q", message.ProtocolId, test.protocolId)
+			}
+			var request BridgeMessage
+			if json.Unmarshal([]byte(test.request), &request) == nil && message.RequestId != request.RequestId {
+				t.Errorf("request_id = %q, want %q", message.RequestId, request.RequestId)
+			}
+
+			if test.hasResponse {
+				if message.Error != nil {
+					t.Fatalf("error = %+v, want response", message.Error)
+				}
+				if got := servingStatus(t, message); got != test.want {
+					t.Errorf("status = %v, want %v", got, test.want)
+				}
+				return
+			}
+
+			if message.Error == nil {
+				t.Fatalf("error = nil, want %v", test.code)
+			}
+			if message.Error.Code != test.code || !strings.Contains(message.Error.Message, test.message) {
+				t.Errorf("error = %v (%v), want %v (%v)", message.Error.Code, message.Error.Message, test.code, test.message)
+			}
+			switch {
+			case test.detailCode == 0 && message.Error.Detail != nil:
+				t.Errorf("detail = %v, want nil", message.Error.Detail)
+			case test.detailCode != 0 && (message.Error.Detail == nil || message.Error.Detail.Code() != test.detailCode):
+				t.Errorf("detail = %v, want code %v", message.Error.Detail, test.detailCode)
+			}
+		})
+	}
+}
+
+func watchSubscription() *StreamSubscription {
+	return &StreamSubscription{
+		ProtocolId:  watchProtocol,
+		Method:      "/grpc.health.v1.Health/Watch",
+		Request:     &healthpb.HealthCheckRequest{},
+		NewResponse: func() proto.Message { return &healthpb.HealthCheckResponse{} },
+	}
+}
+
+func TestBridgeRelayToSession(t *testing.T) {
+
+	fixture := newBridgeFixture(t)
+	conn := fixture.dial(t)
+	session := <-fixture.sessions
+
+	relayErr := make(chan error, 1)
+	go func() {
+		relayErr <- fixture.bridge.RelayToSession(context.Background(), session, watchSubscription())
+	}()
+
+	// stream 의 메시지를 순서대로 전달한다
+	message := readBridgeMessage(t, conn)
+	if message.ProtocolId != watchProtocol || servingStatus(t, message) != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("first frame = %v %s, want SERVING", message.ProtocolId, message.Data)
+	}
+	fixture.grpcServer.HealthRegistry().SetReady(false)
+	if message := readBridgeMessage(t, conn); servingStatus(t, message) != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("second frame = %s, want NOT_SERVING", message.Data)
+	}
+
+	// session 이 종료되면 구독도 종료된다
+	_ = conn.Close()
+	select {
+	case err := <-relayErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("RelayToSession() = %v, want Canceled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("RelayToSession() did not return after the session closed")
+	}
+}
+
+func TestBridgeRelayToServer(t *testing.T) {
+
+	fixture := newBridgeFixture(t)
+	conns := []*websocket.Conn{fixture.dial(t), fixture.dial(t)}
+	for range conns {
+		<-fixture.sessions
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relayErr := make(chan error, 1)
+	go func() {
+		relayErr <- fixture.bridge.RelayToServer(ctx, fixture.wsServer, watchSubscription())
+	}()
+
+	// 모든 session 에 전송한다
+	for index, conn := range conns {
+		if message := readBridgeMessage(t, conn); servingStatus(t, message) != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("session %v frame = %s, want SERVING", index, message.Data)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-relayErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("RelayToServer() = %v, want Canceled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("RelayToServer() did not return after ctx was canceled")
+	}
+}
@@ -0,0 +1,92 @@
+package grpcwrapper
+
+import (
+	"context"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"sync/atomic"
+	"time"
+)
+
+// AccessLogEntry rpc 단위 access log
+type AccessLogEntry struct {
+	Method        string        `json:"method"`
+	Peer          string        `json:"peer"`
+	Code          string        `json:"code"`
+	Error         string        `json:"error,omitempty"`
+	Latency       time.Duration `json:"latency_ns"`
+	RequestCount  int64         `json:"request_count"`
+	RequestBytes  int64         `json:"request_bytes"`
+	ResponseCount int64         `json:"response_count"`
+	ResponseBytes int64         `json:"response_bytes"`
+}
+
+// AccessLogger access log 출력 함수
+type AccessLogger func(entry *AccessLogEntry)
+
+// DefaultAccessLogger grpcwrapper 로거에 info 로 출력
+func DefaultAccessLogger(entry *AccessLogEntry) {
+	logger.Info("grpc access", "method", entry.Method, "peer", entry.Peer, "code", entry.Code, "error", entry.Error,
+		"latency", entry.Latency, "request_count", entry.RequestCount, "request_bytes", entry.RequestBytes,
+		"response_count", entry.ResponseCount, "response_bytes", entry.ResponseBytes)
+}
+
+type accessLogKey struct{}
+
+type accessLogRecord struct {
+	method        string
+	requestCount  int64
+	requestBytes  int64
+	responseCount int64
+	responseBytes int64
+}
+
+// accessLogHandler stats.Handler 로 메시지 크기를 포함한 access log 수집
+type accessLogHandler struct {
+	logger AccessLogger
+}
+
+func (h *accessLogHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, accessLogKey{}, &accessLogRecord{method: info.FullMethodName})
+}
+
+func (h *accessLogHandler) HandleRPC(ctx context.Context, rpcStats stats.RPCStats) {
+
+	record, ok := ctx.Value(accessLogKey{}).(*accessLogRecord)
+	if !ok {
+		return
+	}
+
+	switch rpcStat := rpcStats.(type) {
+	case *stats.InPayload:
+		atomic.AddInt64(&record.requestCount, 1)
+		atomic.AddInt64(&record.requestBytes, int64(rpcStat.WireLength))
+	case *stats.OutPayload:
+		atomic.AddInt64(&record.responseCount, 1)
+		atomic.AddInt64(&record.responseBytes, int64(rpcStat.WireLength))
+	case *stats.End:
+		entry := &AccessLogEntry{
+			Method:        record.method,
+			Code:          status.Code(rpcStat.Error).String(),
+			Latency:       rpcStat.EndTime.Sub(rpcStat.BeginTime),
+			RequestCount:  atomic.LoadInt64(&record.requestCount),
+			RequestBytes:  atomic.LoadInt64(&record.requestBytes),
+			ResponseCount: atomic.LoadInt64(&record.responseCount),
+			ResponseBytes: atomic.LoadInt64(&record.responseBytes),
+		}
+		if rpcStat.Error != nil {
+			entry.Error = rpcStat.Error.Error()
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			entry.Peer = p.Addr.String()
+		}
+		h.logger(entry)
+	}
+}
+
+func (h *accessLogHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *accessLogHandler) HandleConn(context.Context, stats.ConnStats) {}
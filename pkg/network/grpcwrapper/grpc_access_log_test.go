@@ -0,0 +1,111 @@
+package grpcwrapper
+
+import (
+	"context"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLog(t *testing.T) {
+
+	entries := make(chan *AccessLogEntry, 10)
+	server, addr := startGrpcServer(t, nil, WithAccessLog(func(entry *AccessLogEntry) {
+		entries <- entry
+	}))
+	server.HealthRegistry().AddReadinessCheck("database", func(context.Context) error { return nil })
+	client := healthpb.NewHealthClient(dialGrpc(t, addr))
+
+	tests := []struct {
+		service       string
+		code          string
+		error         string
+		responseCount int64
+	}{
+		{service: "database", code: codes.OK.String(), responseCount: 1},
+		{service: "cache", code: codes.NotFound.String(), error: "unknown service"},
+	}
+
+	for _, test := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, _ = client.Check(ctx, &healthpb.HealthCheckRequest{Service: test.service})
+		cancel()
+
+		var entry *AccessLogEntry
+		select {
+		case entry = <-entries:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v: no access log", test.service)
+		}
+		if entry.Method != "/grpc.health.v1.Health/Check" || entry.Code != test.code || !strings.Contains(entry.Error, test.error) {
+			t.Errorf("%v: entry = %+v, want code %v, error %q", test.service, entry, test.code, test.error)
+		}
+		if test.error == "" && entry.Error != "" {
+			t.Errorf("%v: error = %q, want empty", test.service, entry.Error)
+		}
+		if entry.RequestCount != 1 || entry.RequestBytes <= 0 {
+			t.Errorf("%v: request = %v messages, %v bytes", test.service, entry.RequestCount, entry.RequestBytes)
+		}
+		if entry.ResponseCount != test.responseCount || (test.responseCount > 0) != (entry.ResponseBytes > 0) {
+			t.Errorf("%v: response = %v messages, %v bytes, want %v messages", test.service, entry.ResponseCount, entry.ResponseBytes, test.responseCount)
+		}
+		if !strings.HasPrefix(entry.Peer, "127.0.0.1:") || entry.Latency <= 0 {
+			t.Errorf("%v: peer = %q, latency = %v", test.service, entry.Peer, entry.Latency)
+		}
+	}
+}
+
+func TestReflectionAndChannelzOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []GrpcServerOption
+		want    codes.Code
+	}{
+		{name: "enabled", options: []GrpcServerOption{WithReflection(), WithChannelz()}, want: codes.OK},
+		{name: "disabled", want: codes.Unimplemented},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, addr := startGrpcServer(t, nil, test.options...)
+			conn := dialGrpc(t, addr)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// reflection 은 등록된 서비스 목록을 반환한다
+			stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = stream.Send(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := stream.Recv()
+			if status.Code(err) != test.want {
+				t.Fatalf("reflection error = %v, want %v", err, test.want)
+			}
+			if err == nil {
+				var services []string
+				for _, service := range response.GetListServicesResponse().GetService() {
+					services = append(services, service.Name)
+				}
+				if joined := strings.Join(services, ","); !strings.Contains(joined, healthpb.Health_ServiceDesc.ServiceName) {
+					t.Errorf("services = %v, want health service", services)
+				}
+			}
+
+			_, err = channelzpb.NewChannelzClient(conn).GetServers(ctx, &channelzpb.GetServersRequest{})
+			if status.Code(err) != test.want {
+				t.Errorf("channelz error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
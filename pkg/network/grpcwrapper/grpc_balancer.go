@@ -0,0 +1,100 @@
+package grpcwrapper
+
+import (
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/balancer/roundrobin"
+	_ "google.golang.org/grpc/health" // client side health check
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	RoundRobinBalancer  = roundrobin.Name
+	LeastLoadedBalancer = "least_loaded"
+)
+
+func init() {
+	balancer.Register(&leastLoadedBalancerBuilder{})
+}
+
+// leastLoadedBalancerBuilder ClientConn 마다 picker builder 를 생성해서 처리중인 요청 수를 연결별로 유지
+type leastLoadedBalancerBuilder struct{}
+
+func (b *leastLoadedBalancerBuilder) Build(cc balancer.ClientConn, options balancer.BuildOptions) balancer.Balancer {
+	pickerBuilder := &leastLoadedPickerBuilder{backends: map[balancer.SubConn]*loadedSubConn{}}
+	return base.NewBalancerBuilder(LeastLoadedBalancer, pickerBuilder, base.Config{HealthCheck: true}).Build(cc, options)
+}
+
+func (b *leastLoadedBalancerBuilder) Name() string {
+	return LeastLoadedBalancer
+}
+
+// leastLoadedPickerBuilder 처리중인 요청 수가 가장 적은 backend 를 선택.
+// resolver update 로 picker 가 다시 만들어져도 처리중인 요청 수는 SubConn 별로 유지한다
+type leastLoadedPickerBuilder struct {
+	mutex    sync.Mutex
+	backends map[balancer.SubConn]*loadedSubConn
+}
+
+func (b *leastLoadedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	picker := &leastLoadedPicker{}
+	for subConn := range info.ReadySCs {
+		backend, ok := b.backends[subConn]
+		if !ok {
+			backend = &loadedSubConn{subConn: subConn}
+			b.backends[subConn] = backend
+		}
+		picker.backends = append(picker.backends, backend)
+	}
+
+	// 빠진 SubConn 은 처리중인 요청이 모두 끝난 후 정리 (다시 ready 가 되면 같은 수를 사용)
+	for subConn, backend := range b.backends {
+		if _, ok := info.ReadySCs[subConn]; !ok && atomic.LoadInt64(&backend.inFlight) == 0 {
+			delete(b.backends, subConn)
+		}
+	}
+	return picker
+}
+
+type loadedSubConn struct {
+	subConn  balancer.SubConn
+	inFlight int64
+}
+
+type leastLoadedPicker struct {
+	mutex    sync.Mutex
+	backends []*loadedSubConn
+	next     int
+}
+
+func (p *leastLoadedPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+
+	p.mutex.Lock()
+	// 동일한 부하일 때 한쪽으로 몰리지 않도록 시작 위치를 순환
+	count := len(p.backends)
+	selected := p.backends[p.next%count]
+	for index := 1; index < count; index++ {
+		candidate := p.backends[(p.next+index)%count]
+		if atomic.LoadInt64(&candidate.inFlight) < atomic.LoadInt64(&selected.inFlight) {
+			selected = candidate
+		}
+	}
+	p.next = (p.next + 1) % count
+	p.mutex.Unlock()
+
+	atomic.AddInt64(&selected.inFlight, 1)
+	return balancer.PickResult{
+		SubConn: selected.subConn,
+		Done: func(balancer.DoneInfo) {
+			atomic.AddInt64(&selected.inFlight, -1)
+		},
+	}, nil
+}
@@ -0,0 +1,73 @@
+package grpcwrapper
+
+import (
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"testing"
+)
+
+type testSubConn struct {
+	balancer.SubConn
+	name string
+}
+
+func buildLeastLoadedPicker(builder *leastLoadedPickerBuilder, subConns ...*testSubConn) balancer.Picker {
+	info := base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{}}
+	for _, subConn := range subConns {
+		info.ReadySCs[subConn] = base.SubConnInfo{}
+	}
+	return builder.Build(info)
+}
+
+func pick(t *testing.T, picker balancer.Picker) (*testSubConn, func()) {
+	t.Helper()
+	result, err := picker.Pick(balancer.PickInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.SubConn.(*testSubConn), func() {
+		result.Done(balancer.DoneInfo{})
+	}
+}
+
+func TestLeastLoadedPicker(t *testing.T) {
+
+	first, second, third := &testSubConn{name: "first"}, &testSubConn{name: "second"}, &testSubConn{name: "third"}
+	builder := &leastLoadedPickerBuilder{backends: map[balancer.SubConn]*loadedSubConn{}}
+	picker := buildLeastLoadedPicker(builder, first, second, third)
+
+	// 처리중인 요청이 같으면 순환하며 모두 선택된다
+	picked := map[*testSubConn]func(){}
+	for index := 0; index < 3; index++ {
+		subConn, done := pick(t, picker)
+		picked[subConn] = done
+	}
+	if len(picked) != 3 {
+		t.Fatalf("picked %v backends, want 3", len(picked))
+	}
+
+	// 요청이 끝난 backend 가 선택된다
+	picked[second]()
+	if subConn, _ := pick(t, picker); subConn != second {
+		t.Errorf("Pick() = %v, want second", subConn.name)
+	}
+	picked[third]()
+
+	// resolver update 로 picker 가 다시 만들어져도 처리중인 요청 수는 유지된다
+	picker = buildLeastLoadedPicker(builder, first, second, third)
+	if subConn, _ := pick(t, picker); subConn != third {
+		t.Errorf("Pick() after rebuild = %v, want third", subConn.name)
+	}
+
+	// ready 가 아닌 backend 는 선택되지 않는다
+	picker = buildLeastLoadedPicker(builder, first, second)
+	for index := 0; index < 4; index++ {
+		if subConn, _ := pick(t, picker); subConn == third {
+			t.Error("Pick() selected a removed backend")
+		}
+	}
+
+	if _, err := buildLeastLoadedPicker(builder).Pick(balancer.PickInfo{}); err != balancer.ErrNoSubConnAvailable {
+		t.Errorf("Pick() without backends error = %v, want ErrNoSubConnAvailable", err)
+	}
+}
@@ -2,37 +2,304 @@ package grpcwrapper
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"google.golang.org/grpc"
-	"log"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"strings"
+	"sync"
 	"time"
 )
 
-// GrpcRequest Grpc Client 요청
-func GrpcRequest(uri string, timeout int64, callback func(conn *grpc.ClientConn, ctx context.Context) (interface{}, error)) (interface{}, error) {
+// GrpcCallback 연결된 connection 으로 stub 을 호출하는 callback
+type GrpcCallback func(conn *grpc.ClientConn, ctx context.Context) (interface{}, error)
+
+type grpcClientOptions struct {
+	tlsConfig   *tls.Config
+	metadata    map[string]string
+	dialOptions []grpc.DialOption
+
+	// load balancing
+	balancer           string
+	healthCheck        bool
+	healthCheckService string
+}
+
+// GrpcClientOption NewGrpcClient 설정 옵션
+type GrpcClientOption func(options *grpcClientOptions)
+
+// WithClientTLS TLS/mTLS 설정 (NewClientTLSConfig 참고). 지정하지 않으면 insecure 로 연결
+func WithClientTLS(config *tls.Config) GrpcClientOption {
+	return func(options *grpcClientOptions) {
+		options.tlsConfig = config
+	}
+}
+
+func WithClientKeepalive(params keepalive.ClientParameters) GrpcClientOption {
+	return func(options *grpcClientOptions) {
+		options.dialOptions = append(options.dialOptions, grpc.WithKeepaliveParams(params))
+	}
+}
+
+// WithDefaultMetadata 모든 요청에 추가되는 metadata
+func WithDefaultMetadata(md map[string]string) GrpcClientOption {
+	return func(options *grpcClientOptions) {
+		if options.metadata == nil {
+			options.metadata = map[string]string{}
+		}
+		for key, value := range md {
+			options.metadata[key] = value
+		}
+	}
+}
+
+// WithUnaryClientInterceptors RetryUnaryInterceptor, CircuitBreakerUnaryInterceptor 등 등록 순서대로 chain 으로 실행
+func WithUnaryClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) GrpcClientOption {
+	return func(options *grpcClientOptions) {
+		options.dialOptions = append(options.dialOptions, grpc.WithChainUnaryInterceptor(interceptors...))
+	}
+}
+
+// WithLoadBalancing 여러 backend 로 resolve 되는 target (StaticTarget, FileTarget, DNSTarget) 의 분산 정책.
+// RoundRobinBalancer, LeastLoadedBalancer 혹은 등록된 balancer 이름
+func WithLoadBalancing(policy string) GrpcClientOption {
+	return func(options *grpcClientOptions) {
+		options.balancer = policy
+	}
+}
+
+// WithHealthCheck backend 의 grpc health service 를 확인하여 NOT_SERVING 인 backend 를 제외
+func WithHealthCheck(serviceName string) GrpcClientOption {
+	return func(options *grpcClientOptions) {
+		options.healthCheck = true
+		options.healthCheckService = serviceName
+	}
+}
+
+// WithResolver 사용자 정의 resolver 사용 (target 은 builder.Scheme() 으로 시작)
+func WithResolver(builder resolver.Builder) GrpcClientOption {
+	return func(options *grpcClientOptions) {
+		options.dialOptions = append(options.dialOptions, grpc.WithResolvers(builder))
+	}
+}
+
+// WithDialOptions 그 외 grpc.DialOption 직접 지정
+func WithDialOptions(dialOptions ...grpc.DialOption) GrpcClientOption {
+	return func(options *grpcClientOptions) {
+		options.dialOptions = append(options.dialOptions, dialOptions...)
+	}
+}
+
+func (o *grpcClientOptions) serviceConfig() string {
+	var configs []string
+	if o.balancer != "" {
+		configs = append(configs, fmt.Sprintf(`"loadBalancingConfig":[{%q:{}}]`, o.balancer))
+	}
+	if o.healthCheck {
+		configs = append(configs, fmt.Sprintf(`"healthCheckConfig":{"serviceName":%q}`, o.healthCheckService))
+	}
+	if len(configs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(configs, ",") + "}"
+}
+
+// DefaultConnectTimeout ctx 에 deadline 이 없을 때 Conn 이 연결을 기다리는 최대 시간 (연결할 수 없는 target 에서 멈추지 않도록)
+var DefaultConnectTimeout = 20 * time.Second
+
+type grpcConnection struct {
+	ready chan struct{}
+	conn  *grpc.ClientConn
+	err   error
+}
+
+// GrpcClient target 별로 connection 을 재사용하는 client (concurrent safe)
+type GrpcClient struct {
+	mutex       sync.Mutex
+	connections map[string]*grpcConnection
+	options     *grpcClientOptions
+}
+
+func NewGrpcClient(options ...GrpcClientOption) *GrpcClient {
+	clientOptions := &grpcClientOptions{}
+	for _, option := range options {
+		option(clientOptions)
+	}
+	return &GrpcClient{
+		connections: map[string]*grpcConnection{},
+		options:     clientOptions,
+	}
+}
+
+// Conn target 의 connection 을 반환. 없으면 ctx 가 만료될 때까지 (deadline 이 없으면 DefaultConnectTimeout 동안) 연결을 시도
+func (c *GrpcClient) Conn(ctx context.Context, target string) (*grpc.ClientConn, error) {
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultConnectTimeout)
+		defer cancel()
+	}
 
-	// connection 생성
-	connection, err := grpc.Dial(uri, grpc.WithInsecure(), grpc.WithBlock())
+	c.mutex.Lock()
+	connection, ok := c.connections[target]
+	if ok && connection.isShutdown() {
+		delete(c.connections, target)
+		ok = false
+	}
+	if !ok {
+		connection = &grpcConnection{ready: make(chan struct{})}
+		c.connections[target] = connection
+		go c.dial(target, connection)
+	}
+	c.mutex.Unlock()
+
+	select {
+	case <-connection.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if connection.err != nil {
+		return nil, connection.err
+	}
+
+	// 최초 연결 (혹은 재연결) 대기
+	conn := connection.conn
+	for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+		if state == connectivity.Idle {
+			conn.Connect()
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return nil, ctx.Err()
+		}
+	}
+	return conn, nil
+}
+
+func (c *GrpcClient) dial(target string, connection *grpcConnection) {
+
+	defer close(connection.ready)
+
+	dialOptions := []grpc.DialOption{}
+	if c.options.tlsConfig != nil {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(c.options.tlsConfig)))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
+	if serviceConfig := c.options.serviceConfig(); serviceConfig != "" {
+		dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(serviceConfig))
+	}
+	dialOptions = append(dialOptions, c.options.dialOptions...)
+
+	// non-blocking dial. 연결 대기는 Conn 에서 호출자의 ctx 로 제한
+	connection.conn, connection.err = grpc.Dial(target, dialOptions...)
+	if connection.err != nil {
+		logger.Error("grpc dial error", "target", target, "err", connection.err)
+		c.mutex.Lock()
+		if c.connections[target] == connection {
+			delete(c.connections, target)
+		}
+		c.mutex.Unlock()
+	}
+}
+
+func (g *grpcConnection) isShutdown() bool {
+	select {
+	case <-g.ready:
+		return g.err != nil || g.conn.GetState() == connectivity.Shutdown
+	default:
+		return false
+	}
+}
+
+// Request timeout 안에 연결 및 callback 실행. md 는 요청별 metadata.
+// timeout 이 0 이하이면 호출 시간은 제한하지 않고 연결만 DefaultConnectTimeout 으로 제한한다
+func (c *GrpcClient) Request(ctx context.Context, target string, timeout time.Duration,
+	md map[string]string, callback GrpcCallback) (interface{}, error) {
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	connection, err := c.Conn(ctx, target)
 	if err != nil {
-		log.Println("pt cmd connect fail")
+		logger.Error("grpc connect fail", "target", target, "err", err)
 		return nil, err
 	}
-	defer func() {
-		err := connection.Close()
-		if err != nil {
-			log.Printf("grpc client close error (err:%v)", err)
-		}
-	}()
 
-	// timer 설정
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout) * time.Second)
-	defer cancel()
+	ctx = appendMetadata(ctx, c.options.metadata)
+	ctx = appendMetadata(ctx, md)
 
-	// stub callback 실행
 	response, err := callback(connection, ctx)
 	if err != nil {
-		log.Println("pt stub error")
+		logger.Error("grpc stub error", "target", target, "err", err)
 		return nil, err
 	}
-
 	return response, nil
 }
+
+// CloseTarget target 의 connection 종료
+func (c *GrpcClient) CloseTarget(target string) error {
+	c.mutex.Lock()
+	connection, ok := c.connections[target]
+	delete(c.connections, target)
+	c.mutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+	<-connection.ready
+	if connection.conn == nil {
+		return nil
+	}
+	return connection.conn.Close()
+}
+
+// Close 모든 connection 종료
+func (c *GrpcClient) Close() error {
+	c.mutex.Lock()
+	connections := c.connections
+	c.connections = map[string]*grpcConnection{}
+	c.mutex.Unlock()
+
+	var firstErr error
+	for target, connection := range connections {
+		<-connection.ready
+		if connection.conn == nil {
+			continue
+		}
+		if err := connection.conn.Close(); err != nil {
+			logger.Warn("grpc client close error", "target", target, "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func appendMetadata(ctx context.Context, md map[string]string) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+	pairs := make([]string, 0, len(md)*2)
+	for key, value := range md {
+		pairs = append(pairs, key, value)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// DefaultGrpcClient GrpcRequest 에서 사용하는 insecure client.
+// 재시도 등의 정책이 필요하면 WithUnaryClientInterceptors 로 생성한 client 로 교체한다
+var DefaultGrpcClient = NewGrpcClient()
+
+// GrpcRequest Grpc Client 요청 (DefaultGrpcClient 의 connection 을 재사용). timeout 은 연결과 호출을 포함 (seconds)
+func GrpcRequest(uri string, timeout int64, callback func(conn *grpc.ClientConn, ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return DefaultGrpcClient.Request(context.Background(), uri, time.Duration(timeout)*time.Second, nil, callback)
+}
@@ -0,0 +1,299 @@
+package grpcwrapper
+
+import (
+	"context"
+	"github.com/hwangtaeseung/neptune-core/pkg/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen circuit breaker 가 열려 있어 호출하지 않은 경우 (재시도 대상 아님)
+var ErrCircuitOpen = status.Error(codes.Unavailable, "circuit breaker is open")
+
+// RetryPolicy RetryUnaryInterceptor 설정
+type RetryPolicy struct {
+	// 최초 호출을 포함한 최대 시도 횟수 (0 이하면 common.DefaultMaxRetryCount)
+	MaxAttempts int
+
+	// 재시도할 status code
+	RetryableCodes []codes.Code
+
+	// 재시도 대기 시간 (nil 이면 DefaultRetryPolicy 의 backoff)
+	Backoff retry.Backoff
+
+	// 시도별 timeout (0 이면 호출자 ctx 만 적용)
+	PerAttemptTimeout time.Duration
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		RetryableCodes: []codes.Code{codes.Unavailable, codes.DeadlineExceeded},
+		Backoff:        retry.NewExponentialBackoff(100*time.Millisecond, 5*time.Second),
+	}
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	return err != ErrCircuitOpen && containsCode(p.RetryableCodes, status.Code(err))
+}
+
+// RetryUnaryInterceptor 재시도 가능한 status code 에 대해 policy.Backoff 로 재시도 (retry.Do).
+// 호출자의 ctx 가 끝나면 더 이상 시도하지 않고 마지막 grpc 에러를 반환한다.
+// CircuitBreakerUnaryInterceptor 와 함께 사용할 경우 retry 를 먼저 (바깥쪽에) 등록한다
+func RetryUnaryInterceptor(policy *RetryPolicy) grpc.UnaryClientInterceptor {
+
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = DefaultRetryPolicy().Backoff
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		var lastErr error
+		err := retry.Do(ctx, &retry.Policy{
+			MaxAttempts: policy.MaxAttempts,
+			Backoff:     backoff,
+			Retryable:   policy.retryable,
+			OnAttempt: func(attempt *retry.Attempt) {
+				if attempt.Err != nil && !attempt.Final {
+					logger.Warn("grpc call failed. retry", "delay", attempt.Delay, "method", method, "attempt", attempt.Number, "err", attempt.Err)
+				}
+			},
+		}, func(ctx context.Context) error {
+			lastErr = invokeAttempt(ctx, policy.PerAttemptTimeout, method, req, reply, cc, invoker, opts)
+			return lastErr
+		})
+
+		// retry.Do 가 ctx 에러를 반환한 경우에도 grpc status 에러를 유지
+		if err != nil && ctx.Err() != nil {
+			if lastErr != nil {
+				return lastErr
+			}
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return err
+	}
+}
+
+func invokeAttempt(ctx context.Context, timeout time.Duration, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts []grpc.CallOption) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// CircuitBreakerPolicy CircuitBreakerUnaryInterceptor 설정
+type CircuitBreakerPolicy struct {
+	// 연속 실패 횟수가 FailureThreshold 에 도달하면 open
+	FailureThreshold int
+
+	// open 상태 유지 시간. 이후 한 건의 시험 호출 (half-open) 을 허용
+	OpenTimeout time.Duration
+
+	// 실패로 간주할 status code
+	FailureCodes []codes.Code
+}
+
+func DefaultCircuitBreakerPolicy() *CircuitBreakerPolicy {
+	return &CircuitBreakerPolicy{
+		FailureThreshold: 5,
+		OpenTimeout:      10 * time.Second,
+		FailureCodes:     []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.Internal},
+	}
+}
+
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuitBreaker struct {
+	mutex    sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+}
+
+func (b *circuitBreaker) allow(policy *CircuitBreakerPolicy) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < policy.OpenTimeout {
+			return false
+		}
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		// 시험 호출 진행중
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(policy *CircuitBreakerPolicy, target string, failed bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !failed {
+		if b.state != circuitClosed {
+			logger.Info("circuit breaker closed", "target", target)
+		}
+		b.state = circuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= policy.FailureThreshold {
+		if b.state != circuitOpen {
+			logger.Warn("circuit breaker opened", "target", target, "failures", b.failures)
+		}
+		b.state = circuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// CircuitBreakerUnaryInterceptor target 별로 연속 실패시 ErrCircuitOpen 으로 즉시 실패
+func CircuitBreakerUnaryInterceptor(policy *CircuitBreakerPolicy) grpc.UnaryClientInterceptor {
+
+	var mutex sync.Mutex
+	breakers := map[string]*circuitBreaker{}
+
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		target := cc.Target()
+		mutex.Lock()
+		breaker, ok := breakers[target]
+		if !ok {
+			breaker = &circuitBreaker{}
+			breakers[target] = breaker
+		}
+		mutex.Unlock()
+
+		if !breaker.allow(policy) {
+			return ErrCircuitOpen
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		breaker.record(policy, target, err != nil && containsCode(policy.FailureCodes, status.Code(err)))
+		return err
+	}
+}
+
+// HedgingPolicy HedgingUnaryInterceptor 설정
+type HedgingPolicy struct {
+	// 최초 호출을 포함한 최대 동시 호출 수
+	MaxAttempts int
+
+	// 응답이 없을 때 다음 호출을 보내기까지의 대기 시간
+	Delay time.Duration
+
+	// 이 code 로 실패하면 Delay 를 기다리지 않고 다음 호출을 보낸다
+	NonFatalCodes []codes.Code
+
+	// hedging 을 적용할 (idempotent) method full name. 그 외 method 는 그대로 호출
+	IdempotentMethods []string
+}
+
+// HedgingUnaryInterceptor idempotent method 에 대해 Delay 간격으로 중복 호출하여 가장 먼저 성공한 응답을 사용
+func HedgingUnaryInterceptor(policy *HedgingPolicy) grpc.UnaryClientInterceptor {
+
+	methods := map[string]bool{}
+	for _, method := range policy.IdempotentMethods {
+		methods[method] = true
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		replyMessage, ok := reply.(proto.Message)
+		if !methods[method] || !ok || policy.MaxAttempts <= 1 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type hedgeResult struct {
+			reply proto.Message
+			err   error
+		}
+		results := make(chan *hedgeResult, policy.MaxAttempts)
+		send := func() {
+			attemptReply := proto.Clone(replyMessage)
+			go func() {
+				err := invoker(ctx, method, req, attemptReply, cc, opts...)
+				results <- &hedgeResult{reply: attemptReply, err: err}
+			}()
+		}
+
+		send()
+		sent, received := 1, 0
+		var lastErr error
+		for received < sent {
+			var timer *time.Timer
+			var delay <-chan time.Time
+			if sent < policy.MaxAttempts {
+				timer = time.NewTimer(policy.Delay)
+				delay = timer.C
+			}
+
+			var result *hedgeResult
+			select {
+			case result = <-results:
+			case <-delay:
+			case <-ctx.Done():
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+
+			switch {
+			case result != nil:
+				received++
+				if result.err == nil {
+					proto.Reset(replyMessage)
+					proto.Merge(replyMessage, result.reply)
+					return nil
+				}
+				lastErr = result.err
+				if !containsCode(policy.NonFatalCodes, status.Code(result.err)) {
+					return result.err
+				}
+				if sent < policy.MaxAttempts {
+					send()
+					sent++
+				}
+			case ctx.Err() != nil:
+				return status.FromContextError(ctx.Err()).Err()
+			default:
+				// Delay 동안 응답 없음
+				send()
+				sent++
+			}
+		}
+		return lastErr
+	}
+}
+
+func containsCode(codeList []codes.Code, code codes.Code) bool {
+	for _, candidate := range codeList {
+		if candidate == code {
+			return true
+		}
+	}
+	return false
+}
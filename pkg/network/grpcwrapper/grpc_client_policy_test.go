@@ -0,0 +1,250 @@
+package grpcwrapper
+
+import (
+	"context"
+	"github.com/hwangtaeseung/neptune-core/pkg/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// scriptedInvoker 호출 순서대로 codes 의 결과를 반환하는 invoker (codes.OK 는 성공)
+func scriptedInvoker(results ...codes.Code) (grpc.UnaryInvoker, *int32) {
+	var calls int32
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		index := atomic.AddInt32(&calls, 1) - 1
+		if int(index) >= len(results) || results[index] == codes.OK {
+			return nil
+		}
+		return status.Error(results[index], results[index].String())
+	}, &calls
+}
+
+func TestRetryUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		results   []codes.Code
+		wantCode  codes.Code
+		wantCalls int32
+	}{
+		{name: "success", results: []codes.Code{codes.OK}, wantCode: codes.OK, wantCalls: 1},
+		{name: "retry then success", results: []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.OK}, wantCode: codes.OK, wantCalls: 3},
+		{name: "not retryable", results: []codes.Code{codes.InvalidArgument}, wantCode: codes.InvalidArgument, wantCalls: 1},
+		{name: "exhausted", results: []codes.Code{codes.Unavailable, codes.Unavailable, codes.Unavailable, codes.OK}, wantCode: codes.Unavailable, wantCalls: 3},
+	}
+
+	interceptor := RetryUnaryInterceptor(&RetryPolicy{
+		MaxAttempts:    3,
+		RetryableCodes: []codes.Code{codes.Unavailable, codes.DeadlineExceeded},
+		Backoff:        &retry.ConstantBackoff{Delay: time.Millisecond},
+	})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			invoker, calls := scriptedInvoker(test.results...)
+			err := interceptor(context.Background(), "/test.Service/Get", nil, nil, nil, invoker)
+			if status.Code(err) != test.wantCode {
+				t.Errorf("error = %v, want %v", err, test.wantCode)
+			}
+			if *calls != test.wantCalls {
+				t.Errorf("calls = %v, want %v", *calls, test.wantCalls)
+			}
+		})
+	}
+
+	// circuit breaker 가 열려 있으면 재시도하지 않는다
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return ErrCircuitOpen
+	}
+	if err := interceptor(context.Background(), "/test.Service/Get", nil, nil, nil, invoker); err != ErrCircuitOpen {
+		t.Errorf("error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestRetryUnaryInterceptorContext(t *testing.T) {
+
+	interceptor := RetryUnaryInterceptor(&RetryPolicy{
+		MaxAttempts:       5,
+		RetryableCodes:    []codes.Code{codes.Unavailable},
+		Backoff:           &retry.ConstantBackoff{Delay: time.Hour},
+		PerAttemptTimeout: time.Second,
+	})
+
+	// 시도별 timeout 을 적용한다
+	var deadline bool
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		_, deadline = ctx.Deadline()
+		return status.Error(codes.Unavailable, "down")
+	}
+
+	// 대기 중 ctx 가 끝나면 마지막 grpc 에러를 반환한다
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	startTime := time.Now()
+	err := interceptor(ctx, "/test.Service/Get", nil, nil, nil, invoker)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("error = %v, want Unavailable", err)
+	}
+	if elapsed := time.Since(startTime); elapsed > 5*time.Second {
+		t.Errorf("returned after %v, want ctx timeout", elapsed)
+	}
+	if !deadline {
+		t.Error("attempt has no deadline with PerAttemptTimeout")
+	}
+
+	// 시도 전에 ctx 가 끝났으면 ctx 에러를 grpc status 로 반환한다
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := interceptor(canceled, "/test.Service/Get", nil, nil, nil, invoker); status.Code(err) != codes.Canceled {
+		t.Errorf("error = %v, want Canceled", err)
+	}
+}
+
+func TestCircuitBreakerUnaryInterceptor(t *testing.T) {
+
+	openTimeout := 200 * time.Millisecond
+	interceptor := CircuitBreakerUnaryInterceptor(&CircuitBreakerPolicy{
+		FailureThreshold: 2,
+		OpenTimeout:      openTimeout,
+		FailureCodes:     []codes.Code{codes.Unavailable},
+	})
+	conn := dialGrpc(t, "passthrough:///breaker")
+	other := dialGrpc(t, "passthrough:///other")
+
+	steps := []struct {
+		name    string
+		wait    bool
+		result  codes.Code
+		conn    *grpc.ClientConn
+		want    codes.Code
+		invoked bool
+	}{
+		{name: "first failure", result: codes.Unavailable, want: codes.Unavailable, invoked: true},
+		{name: "not a failure code resets", result: codes.NotFound, want: codes.NotFound, invoked: true},
+		{name: "failure", result: codes.Unavailable, want: codes.Unavailable, invoked: true},
+		{name: "threshold opens", result: codes.Unavailable, want: codes.Unavailable, invoked: true},
+		{name: "open", result: codes.OK, want: codes.Unavailable},
+		{name: "other target", result: codes.OK, conn: other, want: codes.OK, invoked: true},
+		{name: "half-open failure reopens", wait: true, result: codes.Unavailable, want: codes.Unavailable, invoked: true},
+		{name: "reopened", result: codes.OK, want: codes.Unavailable},
+		{name: "half-open success closes", wait: true, result: codes.OK, want: codes.OK, invoked: true},
+		{name: "closed", result: codes.Unavailable, want: codes.Unavailable, invoked: true},
+	}
+
+	for _, step := range steps {
+		if step.wait {
+			time.Sleep(openTimeout + 50*time.Millisecond)
+		}
+		target := conn
+		if step.conn != nil {
+			target = step.conn
+		}
+		invoker, calls := scriptedInvoker(step.result)
+		err := interceptor(context.Background(), "/test.Service/Get", nil, nil, target, invoker)
+		if status.Code(err) != step.want {
+			t.Errorf("%v: error = %v, want %v", step.name, err, step.want)
+		}
+		if (*calls == 1) != step.invoked {
+			t.Errorf("%v: invoked = %v, want %v", step.name, *calls == 1, step.invoked)
+		}
+		if !step.invoked && err != ErrCircuitOpen {
+			t.Errorf("%v: error = %v, want ErrCircuitOpen", step.name, err)
+		}
+	}
+}
+
+func TestHedgingUnaryInterceptor(t *testing.T) {
+
+	const method = "/test.Service/Get"
+	tests := []struct {
+		name      string
+		method    string
+		delay     time.Duration
+		attempt   func(number int32, ctx context.Context, reply *wrapperspb.StringValue) error
+		want      string
+		wantCode  codes.Code
+		wantCalls int32
+	}{
+		{
+			// 첫 호출이 Delay 안에 응답하지 않으면 다음 호출의 응답을 사용한다
+			name: "slow first attempt", method: method, delay: 50 * time.Millisecond,
+			attempt: func(number int32, ctx context.Context, reply *wrapperspb.StringValue) error {
+				if number == 1 {
+					<-ctx.Done()
+					return status.FromContextError(ctx.Err()).Err()
+				}
+				reply.Value = "second"
+				return nil
+			},
+			want: "second", wantCalls: 2,
+		},
+		{
+			// non-fatal 에러는 Delay 를 기다리지 않고 다음 호출을 보낸다
+			name: "non-fatal error", method: method, delay: time.Hour,
+			attempt: func(number int32, ctx context.Context, reply *wrapperspb.StringValue) error {
+				if number == 1 {
+					return status.Error(codes.Unavailable, "down")
+				}
+				reply.Value = "second"
+				return nil
+			},
+			want: "second", wantCalls: 2,
+		},
+		{
+			name: "fatal error", method: method, delay: time.Hour,
+			attempt: func(number int32, ctx context.Context, reply *wrapperspb.StringValue) error {
+				return status.Error(codes.InvalidArgument, "invalid")
+			},
+			wantCode: codes.InvalidArgument, wantCalls: 1,
+		},
+		{
+			name: "all attempts failed", method: method, delay: time.Hour,
+			attempt: func(number int32, ctx context.Context, reply *wrapperspb.StringValue) error {
+				return status.Error(codes.Unavailable, "down")
+			},
+			wantCode: codes.Unavailable, wantCalls: 3,
+		},
+		{
+			// idempotent 가 아닌 method 는 한번만 호출한다
+			name: "not idempotent", method: "/test.Service/Create", delay: time.Millisecond,
+			attempt: func(number int32, ctx context.Context, reply *wrapperspb.StringValue) error {
+				time.Sleep(50 * time.Millisecond)
+				reply.Value = "only"
+				return nil
+			},
+			want: "only", wantCalls: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			interceptor := HedgingUnaryInterceptor(&HedgingPolicy{
+				MaxAttempts:       3,
+				Delay:             test.delay,
+				NonFatalCodes:     []codes.Code{codes.Unavailable},
+				IdempotentMethods: []string{method},
+			})
+			// 먼저 응답한 호출로 반환된 후에도 남은 호출이 끝나지 않았을 수 있으므로 test 를 참조하지 않는다
+			attempt := test.attempt
+			var calls int32
+			invoker := func(ctx context.Context, _ string, _, reply interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				return attempt(atomic.AddInt32(&calls, 1), ctx, reply.(*wrapperspb.StringValue))
+			}
+
+			reply := &wrapperspb.StringValue{}
+			err := interceptor(context.Background(), test.method, nil, reply, nil, invoker)
+			if status.Code(err) != test.wantCode {
+				t.Errorf("error = %v, want %v", err, test.wantCode)
+			}
+			if reply.Value != test.want {
+				t.Errorf("reply = %q, want %q", reply.Value, test.want)
+			}
+			if got := atomic.LoadInt32(&calls); got != test.wantCalls {
+				t.Errorf("calls = %v, want %v", got, test.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package grpcwrapper
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGrpcClientConnectTimeout(t *testing.T) {
+
+	defaultConnectTimeout := DefaultConnectTimeout
+	DefaultConnectTimeout = 200 * time.Millisecond
+	defer func() {
+		DefaultConnectTimeout = defaultConnectTimeout
+	}()
+
+	client := NewGrpcClient()
+	defer func() {
+		_ = client.Close()
+	}()
+
+	// timeout 이 0 이어도 연결할 수 없는 target 에서 멈추지 않는다
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Request(context.Background(), "127.0.0.1:1", 0, nil, func(conn *grpc.ClientConn, ctx context.Context) (interface{}, error) {
+			return nil, nil
+		})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Request() error = %v, want DeadlineExceeded", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Request() with timeout 0 did not return")
+	}
+}
+
+func TestGrpcClientConnReuse(t *testing.T) {
+
+	_, addr := startGrpcServer(t, nil)
+	client := NewGrpcClient()
+	defer func() {
+		_ = client.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 동시에 요청해도 target 별로 하나의 connection 을 사용한다
+	conns := make([]*grpc.ClientConn, 10)
+	var waitGroup sync.WaitGroup
+	for index := range conns {
+		waitGroup.Add(1)
+		go func(index int) {
+			defer waitGroup.Done()
+			conn, err := client.Conn(ctx, addr)
+			if err != nil {
+				t.Error(err)
+			}
+			conns[index] = conn
+		}(index)
+	}
+	waitGroup.Wait()
+	for _, conn := range conns[1:] {
+		if conn != conns[0] {
+			t.Fatal("Conn() returned different connections for the same target")
+		}
+	}
+
+	// CloseTarget 후에는 새로 연결한다
+	if err := client.CloseTarget(addr); err != nil {
+		t.Fatal(err)
+	}
+	reconnected, err := client.Conn(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reconnected == conns[0] {
+		t.Error("Conn() after CloseTarget returned the closed connection")
+	}
+
+	// 외부에서 닫힌 connection 도 다시 연결한다
+	_ = reconnected.Close()
+	conn, err := client.Conn(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn == reconnected {
+		t.Error("Conn() returned a shut down connection")
+	}
+}
+
+func TestGrpcClientRequestMetadata(t *testing.T) {
+
+	received := make(chan metadata.MD, 1)
+	_, addr := startGrpcServer(t, nil, WithUnaryInterceptors(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			received <- md
+			return handler(ctx, req)
+		}))
+
+	client := NewGrpcClient(WithDefaultMetadata(map[string]string{"client": "neptune", "trace": "default"}))
+	defer func() {
+		_ = client.Close()
+	}()
+
+	response, err := client.Request(context.Background(), addr, 5*time.Second, map[string]string{"request": "1"},
+		func(conn *grpc.ClientConn, ctx context.Context) (interface{}, error) {
+			return healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.(*healthpb.HealthCheckResponse).Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Request() = %v, want SERVING", response)
+	}
+
+	md := <-received
+	for key, want := range map[string]string{"client": "neptune", "trace": "default", "request": "1"} {
+		if got := md.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("metadata %v = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package grpcwrapper
+
+import (
+	"context"
+	"errors"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"strconv"
+	"time"
+)
+
+// ErrorDomain ErrorInfo detail 의 domain
+const ErrorDomain = "neptune"
+
+// RetryDelay 재시도 가능한 에러의 RetryInfo detail 에 넣는 지연 시간
+var RetryDelay = time.Second
+
+var categoryCodes = map[string]codes.Code{
+	common.CategoryInvalidArgument:   codes.InvalidArgument,
+	common.CategoryNotFound:          codes.NotFound,
+	common.CategoryTimeout:           codes.DeadlineExceeded,
+	common.CategoryCanceled:          codes.Canceled,
+	common.CategoryUnavailable:       codes.Unavailable,
+	common.CategoryResourceExhausted: codes.ResourceExhausted,
+	common.CategoryInternal:          codes.Internal,
+}
+
+// ToGrpcStatus err 를 category 에 해당하는 code 의 status 로 변환. 이미 grpc status 이면 그대로 반환하며,
+// NeptuneError 정보는 ErrorInfo detail (재시도 가능하면 RetryInfo 도) 에 담는다
+func ToGrpcStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	if grpcStatus, ok := status.FromError(err); ok {
+		return grpcStatus
+	}
+
+	neptuneError := common.AsNeptuneError(err)
+	code, ok := categoryCodes[neptuneError.Category()]
+	if !ok {
+		code = codes.Internal
+	}
+
+	errorInfo := &errdetails.ErrorInfo{
+		Reason: strconv.Itoa(neptuneError.Code()),
+		Domain: ErrorDomain,
+		Metadata: map[string]string{
+			"message":  neptuneError.Message(),
+			"action":   neptuneError.Action(),
+			"category": neptuneError.Category(),
+		},
+	}
+	if neptuneError.SysErr() != nil {
+		errorInfo.Metadata["error"] = neptuneError.SysErr().Error()
+	}
+
+	grpcStatus := status.New(code, neptuneError.Error())
+	var detailed *status.Status
+	var detailErr error
+	if neptuneError.Retryable() {
+		detailed, detailErr = grpcStatus.WithDetails(errorInfo, &errdetails.RetryInfo{RetryDelay: durationpb.New(RetryDelay)})
+	} else {
+		detailed, detailErr = grpcStatus.WithDetails(errorInfo)
+	}
+	if detailErr == nil {
+		grpcStatus = detailed
+	} else {
+		logger.Warn("grpc status detail error", "err", detailErr)
+	}
+	return grpcStatus
+}
+
+// FromGrpcStatus client 에서 받은 에러를 NeptuneError 로 복원 (원래 에러는 문자열로만 복원된다).
+// ErrorInfo detail 이 없으면 ErrUnknown 으로 감싸고 grpc code 로 category 를 정한다
+func FromGrpcStatus(err error) *common.NeptuneError {
+	if err == nil {
+		return nil
+	}
+	grpcStatus, ok := status.FromError(err)
+	if !ok {
+		return common.AsNeptuneError(err)
+	}
+
+	for _, detail := range grpcStatus.Details() {
+		errorInfo, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || errorInfo.Domain != ErrorDomain {
+			continue
+		}
+		code, err := strconv.Atoi(errorInfo.Reason)
+		if err != nil {
+			continue
+		}
+		var sysErr error
+		if message, ok := errorInfo.Metadata["error"]; ok {
+			sysErr = errors.New(message)
+		}
+		return common.New(code, errorInfo.Metadata["message"], errorInfo.Metadata["action"]).
+			SetCategory(errorInfo.Metadata["category"]).Copy(sysErr)
+	}
+
+	neptuneError := common.ErrUnknown.Copy(grpcStatus.Err())
+	for category, code := range categoryCodes {
+		if code == grpcStatus.Code() {
+			neptuneError.SetCategory(category)
+		}
+	}
+	if grpcStatus.Code() == codes.Unavailable || grpcStatus.Code() == codes.ResourceExhausted {
+		neptuneError.SetAction(common.ActionRetry)
+	}
+	return neptuneError
+}
+
+// ErrorUnaryInterceptor handler 가 반환한 에러를 ToGrpcStatus 로 변환
+func ErrorUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		response, err := handler(ctx, req)
+		if err != nil {
+			return response, ToGrpcStatus(err).Err()
+		}
+		return response, nil
+	}
+}
+
+func ErrorStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, stream); err != nil {
+			return ToGrpcStatus(err).Err()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package grpcwrapper
+
+import (
+	"errors"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestGrpcStatusRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      codes.Code
+		retryInfo bool
+	}{
+		{name: "invalid argument", err: common.ErrConfigInvalid.Copy(errors.New("port")), code: codes.InvalidArgument},
+		{name: "retryable timeout", err: common.ErrExecTimeout.Copy(errors.New("hang")), code: codes.DeadlineExceeded, retryInfo: true},
+		{name: "plain error", err: errors.New("plain"), code: codes.Internal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grpcStatus := ToGrpcStatus(test.err)
+			if grpcStatus.Code() != test.code {
+				t.Errorf("code = %v, want %v", grpcStatus.Code(), test.code)
+			}
+
+			var retryInfo *errdetails.RetryInfo
+			for _, detail := range grpcStatus.Details() {
+				if info, ok := detail.(*errdetails.RetryInfo); ok {
+					retryInfo = info
+				}
+			}
+			if test.retryInfo != (retryInfo != nil) {
+				t.Fatalf("RetryInfo = %v, want present %v", retryInfo, test.retryInfo)
+			}
+			if retryInfo != nil && retryInfo.RetryDelay.AsDuration() != RetryDelay {
+				t.Errorf("RetryDelay = %v, want %v", retryInfo.RetryDelay.AsDuration(), RetryDelay)
+			}
+
+			want := common.AsNeptuneError(test.err)
+			got := FromGrpcStatus(grpcStatus.Err())
+			if got.Code() != want.Code() || got.Category() != want.Category() || got.Retryable() != want.Retryable() ||
+				got.SysErr().Error() != want.SysErr().Error() {
+				t.Errorf("FromGrpcStatus() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	// ErrorInfo 가 없는 status 는 code 로 category 와 action 을 정한다
+	got := FromGrpcStatus(status.Error(codes.Unavailable, "down"))
+	if !errors.Is(got, common.ErrUnknown) || got.Category() != common.CategoryUnavailable || !got.Retryable() {
+		t.Errorf("FromGrpcStatus(unavailable) = %v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package grpcwrapper
+
+import (
+	"context"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// healthWatchInterval Watch 스트림 상태 재점검 주기
+const healthWatchInterval = time.Second
+
+// healthService common.HealthRegistry 기반 표준 grpc health service
+type healthService struct {
+	healthpb.UnimplementedHealthServer
+	server *GrpcServer
+}
+
+func (h *healthService) Check(ctx context.Context, request *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	servingStatus, err := h.evaluate(ctx, request.GetService())
+	if err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+func (h *healthService) Watch(request *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+
+	lastStatus := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		servingStatus, err := h.evaluate(stream.Context(), request.GetService())
+		if err != nil {
+			servingStatus = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if servingStatus != lastStatus {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+			lastStatus = servingStatus
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case <-ticker.C:
+		}
+	}
+}
+
+// evaluate service 가 "" 이면 전체 readiness, 그 외에는 이름으로 등록된 점검 항목
+func (h *healthService) evaluate(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+
+	registry := h.server.health
+
+	var report *common.HealthReport
+	if service == "" {
+		report = registry.CheckReadiness(ctx)
+	} else {
+		var ok bool
+		if report, ok = registry.Check(ctx, service); !ok {
+			return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, status.Error(codes.NotFound, "unknown service")
+		}
+		if !registry.IsReady() {
+			return healthpb.HealthCheckResponse_NOT_SERVING, nil
+		}
+	}
+
+	if report.Healthy() {
+		return healthpb.HealthCheckResponse_SERVING, nil
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING, nil
+}
@@ -0,0 +1,148 @@
+package grpcwrapper
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LoggingUnaryInterceptor rpc 요청 결과 및 처리 시간 로깅
+func LoggingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		startTime := time.Now()
+		response, err := handler(ctx, req)
+		logger.Info("grpc unary call", "method", info.FullMethod, "code", status.Code(err),
+			"duration", time.Since(startTime), "err", err)
+		return response, err
+	}
+}
+
+func LoggingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		startTime := time.Now()
+		err := handler(srv, stream)
+		logger.Info("grpc stream call", "method", info.FullMethod, "code", status.Code(err),
+			"duration", time.Since(startTime), "err", err)
+		return err
+	}
+}
+
+// RecoveryUnaryInterceptor handler 의 panic 을 codes.Internal 에러로 변환
+func RecoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.Error("grpc panic recovered", "method", info.FullMethod, "panic", recovered, "stack", string(debug.Stack()))
+				err = status.Errorf(codes.Internal, "panic : %v", recovered)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func RecoveryStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.Error("grpc panic recovered", "method", info.FullMethod, "panic", recovered, "stack", string(debug.Stack()))
+				err = status.Errorf(codes.Internal, "panic : %v", recovered)
+			}
+		}()
+		return handler(srv, stream)
+	}
+}
+
+// DurationRecorder rpc 처리 시간 수집 함수
+type DurationRecorder func(fullMethod string, code codes.Code, duration time.Duration)
+
+func MetricsUnaryInterceptor(recorder DurationRecorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		startTime := time.Now()
+		response, err := handler(ctx, req)
+		recorder(info.FullMethod, status.Code(err), time.Since(startTime))
+		return response, err
+	}
+}
+
+func MetricsStreamInterceptor(recorder DurationRecorder) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		startTime := time.Now()
+		err := handler(srv, stream)
+		recorder(info.FullMethod, status.Code(err), time.Since(startTime))
+		return err
+	}
+}
+
+// MethodMetrics method 별 처리 시간 통계
+type MethodMetrics struct {
+	Method        string           `json:"method"`
+	Count         int64            `json:"count"`
+	ErrorCount    int64            `json:"error_count"`
+	TotalDuration time.Duration    `json:"total_duration"`
+	MaxDuration   time.Duration    `json:"max_duration"`
+	Codes         map[string]int64 `json:"codes"`
+}
+
+func (m *MethodMetrics) AverageDuration() time.Duration {
+	if m.Count == 0 {
+		return 0
+	}
+	return m.TotalDuration / time.Duration(m.Count)
+}
+
+// RequestMetrics 메모리 기반 처리 시간 수집기 (Record 를 DurationRecorder 로 사용)
+type RequestMetrics struct {
+	mutex   sync.Mutex
+	methods map[string]*MethodMetrics
+}
+
+func NewRequestMetrics() *RequestMetrics {
+	return &RequestMetrics{
+		methods: map[string]*MethodMetrics{},
+	}
+}
+
+func (r *RequestMetrics) Record(fullMethod string, code codes.Code, duration time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	metrics, ok := r.methods[fullMethod]
+	if !ok {
+		metrics = &MethodMetrics{Method: fullMethod, Codes: map[string]int64{}}
+		r.methods[fullMethod] = metrics
+	}
+	metrics.Count++
+	if code != codes.OK {
+		metrics.ErrorCount++
+	}
+	metrics.TotalDuration += duration
+	if duration > metrics.MaxDuration {
+		metrics.MaxDuration = duration
+	}
+	metrics.Codes[code.String()]++
+}
+
+// Snapshot method 이름 순으로 정렬된 통계 복사본
+func (r *RequestMetrics) Snapshot() []*MethodMetrics {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	snapshot := make([]*MethodMetrics, 0, len(r.methods))
+	for _, metrics := range r.methods {
+		copied := *metrics
+		copied.Codes = make(map[string]int64, len(metrics.Codes))
+		for code, count := range metrics.Codes {
+			copied.Codes[code] = count
+		}
+		snapshot = append(snapshot, &copied)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Method < snapshot[j].Method
+	})
+	return snapshot
+}
@@ -0,0 +1,203 @@
+package grpcwrapper
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"google.golang.org/grpc/resolver"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	StaticScheme = "static"
+	FileScheme   = "file"
+	DNSScheme    = "dns"
+)
+
+// DefaultFileWatchInterval file resolver 의 target 파일 변경 확인 주기
+const DefaultFileWatchInterval = 5 * time.Second
+
+func init() {
+	resolver.Register(&staticResolverBuilder{})
+	resolver.Register(NewFileResolverBuilder(DefaultFileWatchInterval))
+}
+
+// StaticTarget 고정된 주소 목록 target (ex: static:///host1:port,host2:port)
+func StaticTarget(addresses ...string) string {
+	return fmt.Sprintf("%v:///%v", StaticScheme, strings.Join(addresses, ","))
+}
+
+// FileTarget 파일에 기록된 주소 목록 target. 파일은 한 줄에 주소 하나 ('#' 이후는 주석)
+func FileTarget(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+	return fmt.Sprintf("%v://%v", FileScheme, filepath.ToSlash(path))
+}
+
+// DNSTarget dns 조회 결과 전체를 대상으로 하는 target
+func DNSTarget(hostPort string) string {
+	return fmt.Sprintf("%v:///%v", DNSScheme, hostPort)
+}
+
+// //////////////////
+// static resolver
+// //////////////////
+type staticResolverBuilder struct{}
+
+func (b *staticResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	var addresses []resolver.Address
+	for _, address := range strings.Split(strings.TrimPrefix(target.URL.Path, "/"), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, resolver.Address{Addr: address})
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("empty static target (target:%v)", target.URL.String())
+	}
+	if err := cc.UpdateState(resolver.State{Addresses: addresses}); err != nil {
+		logger.Warn("static resolver update error", "err", err)
+	}
+	return &staticResolver{}, nil
+}
+
+func (b *staticResolverBuilder) Scheme() string {
+	return StaticScheme
+}
+
+type staticResolver struct{}
+
+func (r *staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *staticResolver) Close() {}
+
+// //////////////////
+// file resolver
+// //////////////////
+type fileResolverBuilder struct {
+	interval time.Duration
+}
+
+// NewFileResolverBuilder interval 주기로 파일을 확인하는 file resolver (WithResolver 로 주기 변경시 사용)
+func NewFileResolverBuilder(interval time.Duration) resolver.Builder {
+	return &fileResolverBuilder{interval: interval}
+}
+
+func (b *fileResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	path := filepath.FromSlash(target.URL.Path)
+	if path == "" {
+		return nil, fmt.Errorf("empty file target (target:%v)", target.URL.String())
+	}
+	r := &fileResolver{
+		path:     path,
+		cc:       cc,
+		interval: b.interval,
+		resolve:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	r.update()
+	go r.watch()
+	return r, nil
+}
+
+func (b *fileResolverBuilder) Scheme() string {
+	return FileScheme
+}
+
+type fileResolver struct {
+	path     string
+	cc       resolver.ClientConn
+	interval time.Duration
+	resolve  chan struct{}
+	done     chan struct{}
+	once     sync.Once
+
+	modTime   time.Time
+	addresses []string
+}
+
+func (r *fileResolver) watch() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		case <-r.resolve:
+		}
+		r.update()
+	}
+}
+
+func (r *fileResolver) update() {
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		logger.Warn("file resolver stat error", "path", r.path, "err", err)
+		r.cc.ReportError(err)
+		return
+	}
+	if info.ModTime().Equal(r.modTime) {
+		return
+	}
+
+	addresses, err := readTargetFile(r.path)
+	if err != nil {
+		logger.Warn("file resolver read error", "path", r.path, "err", err)
+		r.cc.ReportError(err)
+		return
+	}
+	r.modTime = info.ModTime()
+	if reflect.DeepEqual(addresses, r.addresses) {
+		return
+	}
+	r.addresses = addresses
+
+	state := resolver.State{}
+	for _, address := range addresses {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: address})
+	}
+	logger.Info("file resolver updated", "path", r.path, "addresses", addresses)
+	if err := r.cc.UpdateState(state); err != nil {
+		logger.Warn("file resolver update error", "err", err)
+	}
+}
+
+func (r *fileResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolve <- struct{}{}:
+	default:
+	}
+}
+
+func (r *fileResolver) Close() {
+	r.once.Do(func() {
+		close(r.done)
+	})
+}
+
+func readTargetFile(path string) ([]string, error) {
+	fileBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []string
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			addresses = append(addresses, line)
+		}
+	}
+	return addresses, scanner.Err()
+}
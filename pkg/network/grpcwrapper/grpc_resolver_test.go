@@ -0,0 +1,175 @@
+package grpcwrapper
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingBackend 처리한 rpc 수를 세는 서버
+type countingBackend struct {
+	server *GrpcServer
+	addr   string
+	calls  int32
+}
+
+func startCountingBackend(t *testing.T) *countingBackend {
+	t.Helper()
+	backend := &countingBackend{}
+	backend.server, backend.addr = startGrpcServer(t, nil, WithUnaryInterceptors(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			atomic.AddInt32(&backend.calls, 1)
+			return handler(ctx, req)
+		}))
+	return backend
+}
+
+func (b *countingBackend) count() int32 {
+	return atomic.LoadInt32(&b.calls)
+}
+
+func checkHealth(t *testing.T, client *GrpcClient, target string) {
+	t.Helper()
+	_, err := client.Request(context.Background(), target, 5*time.Second, nil,
+		func(conn *grpc.ClientConn, ctx context.Context) (interface{}, error) {
+			return healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitCalls condition 이 만족될 때까지 target 으로 요청
+func waitCalls(t *testing.T, client *GrpcClient, target string, condition func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !condition(); {
+		if time.Now().After(deadline) {
+			t.Fatal("requests were not routed as expected")
+		}
+		checkHealth(t, client, target)
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStaticResolver(t *testing.T) {
+	for _, policy := range []string{RoundRobinBalancer, LeastLoadedBalancer} {
+		t.Run(policy, func(t *testing.T) {
+
+			first, second := startCountingBackend(t), startCountingBackend(t)
+			client := NewGrpcClient(WithLoadBalancing(policy))
+			defer func() {
+				_ = client.Close()
+			}()
+
+			// 모든 backend 로 분산된다
+			target := StaticTarget(first.addr, " "+second.addr+" ")
+			waitCalls(t, client, target, func() bool {
+				return first.count() > 0 && second.count() > 0
+			})
+		})
+	}
+
+	// 주소가 없는 target 은 연결하지 않는다
+	client := NewGrpcClient()
+	defer func() {
+		_ = client.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.Conn(ctx, StaticTarget()); err == nil || ctx.Err() != nil {
+		t.Errorf("Conn(%v) error = %v, want resolver error", StaticTarget(), err)
+	}
+}
+
+func TestHealthCheckBalancing(t *testing.T) {
+
+	serving, notServing := startCountingBackend(t), startCountingBackend(t)
+	notServing.server.HealthRegistry().SetReady(false)
+
+	client := NewGrpcClient(WithLoadBalancing(RoundRobinBalancer), WithHealthCheck(""))
+	defer func() {
+		_ = client.Close()
+	}()
+
+	// NOT_SERVING backend 로는 요청하지 않는다
+	target := StaticTarget(serving.addr, notServing.addr)
+	for index := 0; index < 10; index++ {
+		checkHealth(t, client, target)
+	}
+	if serving.count() != 10 || notServing.count() != 0 {
+		t.Errorf("calls = %v, %v, want 10, 0", serving.count(), notServing.count())
+	}
+}
+
+func TestFileResolver(t *testing.T) {
+
+	first, second := startCountingBackend(t), startCountingBackend(t)
+	path := filepath.Join(t.TempDir(), "backends")
+	writeTargets := func(content string, modTime time.Time) {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := NewGrpcClient(WithResolver(NewFileResolverBuilder(50 * time.Millisecond)))
+	defer func() {
+		_ = client.Close()
+	}()
+	target := FileTarget(path)
+
+	// 파일이 나중에 만들어져도 연결한다
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		writeTargets("# backends\n"+first.addr+"\n", time.Now())
+	}()
+	checkHealth(t, client, target)
+	if first.count() != 1 {
+		t.Fatalf("first calls = %v, want 1", first.count())
+	}
+
+	// 파일이 바뀌면 새 주소로 요청한다
+	writeTargets(second.addr+" # moved\n", time.Now().Add(time.Minute))
+	waitCalls(t, client, target, func() bool {
+		return second.count() > 0
+	})
+}
+
+func TestReadTargetFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "lines", content: "host1:1\nhost2:2\n", want: []string{"host1:1", "host2:2"}},
+		{name: "comments and blanks", content: "# backends\n\n  host1:1  # primary\n#host2:2\n\thost3:3", want: []string{"host1:1", "host3:3"}},
+		{name: "empty", content: "# none\n", want: nil},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "backends")
+		if err := ioutil.WriteFile(path, []byte(test.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readTargetFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: readTargetFile() = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	if _, err := readTargetFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("readTargetFile(missing) error = nil")
+	}
+}
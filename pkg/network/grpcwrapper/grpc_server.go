@@ -1,42 +1,144 @@
 package grpcwrapper
 
 import (
+	"context"
+	"errors"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
 	"google.golang.org/grpc"
-	"log"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"net"
+	"net/http"
 )
 
+// logger grpcwrapper package 로거 (logging.Configure("grpcwrapper", ...) 로 설정)
+var logger = logging.For("grpcwrapper")
+
 type GrpcServer struct {
 	listener net.Listener
-	server *grpc.Server
+	server   *grpc.Server
+	health   *common.HealthRegistry
+}
+
+func NewGrpcServer(uri string, setupCallback func(server *grpc.Server), options ...GrpcServerOption) (*GrpcServer, error) {
+	server := &GrpcServer{
+		health: common.NewHealthRegistry(),
+	}
+	if err := server.setup(uri, setupCallback, options); err != nil {
+		return nil, err
+	}
+	return server, nil
 }
 
-func NewGrpcServer(uri string, setupCallback func(server *grpc.Server)) *GrpcServer {
-	server := &GrpcServer{}
-	server.setup(uri, setupCallback)
+// NewGrpcHandlerServer listener 없이 http.Handler (ServeHTTP) 로만 동작하는 서버 (multiplex.MultiplexServer 에서 사용).
+// TLS 는 http 서버에서 처리하므로 WithServerTLS 는 적용되지 않는다
+func NewGrpcHandlerServer(setupCallback func(server *grpc.Server), options ...GrpcServerOption) *GrpcServer {
+	server := &GrpcServer{
+		health: common.NewHealthRegistry(),
+	}
+	_ = server.setup("", setupCallback, options)
 	return server
 }
 
-func (s *GrpcServer) setup(uri string, setupCallback func(server *grpc.Server)) {
-	listener, err := net.Listen("tcp", uri)
-	if err != nil {
-		log.Panicf("cmd listen error. %+v", err)
+func (s *GrpcServer) setup(uri string, setupCallback func(server *grpc.Server), options []GrpcServerOption) error {
+
+	serverOptions := &grpcServerOptions{}
+	for _, option := range options {
+		option(serverOptions)
 	}
-	s.listener = listener
-	s.server = grpc.NewServer()
-	setupCallback(s.server)
 
-	log.Printf("grpc cmd setup completed. (uri=%s)", uri)
+	if uri != "" {
+		listener, err := net.Listen("tcp", uri)
+		if err != nil {
+			logger.Error("grpc listen error", "uri", uri, "err", err)
+			return err
+		}
+		s.listener = listener
+	}
+	s.server = grpc.NewServer(serverOptions.build()...)
+	healthpb.RegisterHealthServer(s.server, &healthService{server: s})
+	if serverOptions.channelz {
+		channelzservice.RegisterChannelzServiceToServer(s.server)
+	}
+	if setupCallback != nil {
+		setupCallback(s.server)
+	}
+	// reflection 은 등록된 모든 서비스를 대상으로 하므로 마지막에 등록
+	if serverOptions.reflection {
+		reflection.Register(s.server)
+	}
+
+	logger.Info("grpc server setup completed", "uri", uri, "tls", serverOptions.tlsConfig != nil,
+		"reflection", serverOptions.reflection, "channelz", serverOptions.channelz, "access_log", serverOptions.accessLogger != nil)
+	return nil
 }
 
-func (s *GrpcServer) Run() {
-	log.Printf("grpc cmd started. (cmd=%+v)", s)
-	if err := s.server.Serve(s.listener); err != nil {
-		log.Panicf("cmd run failed. %+v", err)
+// Addr listen 중인 주소 (port 0 으로 listen 한 경우 실제 port 확인용)
+func (s *GrpcServer) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
 	}
+	return s.listener.Addr()
 }
 
-func (s *GrpcServer) Shutdown() {
-	s.server.Stop()
-	log.Println("grpcwrapper cmd has been stopped.")
-}
\ No newline at end of file
+// ServeHTTP HTTP/2 요청을 grpc 로 처리 (NewGrpcHandlerServer 참고)
+func (s *GrpcServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	s.server.ServeHTTP(writer, request)
+}
+
+// SetHealthRegistry WSServer 등과 상태 점검 목록을 공유할 때 사용
+func (s *GrpcServer) SetHealthRegistry(registry *common.HealthRegistry) *GrpcServer {
+	s.health = registry
+	return s
+}
+
+func (s *GrpcServer) HealthRegistry() *common.HealthRegistry {
+	return s.health
+}
+
+// Run Serve 가 종료될 때까지 block. Shutdown 에 의한 종료는 nil 반환
+func (s *GrpcServer) Run() error {
+	if s.listener == nil {
+		return errors.New("grpc server has no listener (use ServeHTTP)")
+	}
+	logger.Info("grpc server started", "listen", s.listener.Addr())
+	if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		logger.Error("grpc server run failed", "err", err)
+		return err
+	}
+	return nil
+}
+
+// Shutdown 처리중인 rpc 를 기다린 후 종료 (GracefulStop). ctx 가 만료되면 강제 종료 (Stop).
+// NewGrpcHandlerServer 로 생성한 경우에는 즉시 Stop
+func (s *GrpcServer) Shutdown(ctx context.Context) error {
+
+	// readiness off
+	s.health.SetReady(false)
+
+	// ServeHTTP 연결은 GracefulStop 을 지원하지 않음. 처리중인 요청은 호출자가 먼저 정리한다
+	if s.listener == nil {
+		s.server.Stop()
+		logger.Info("grpc handler has been stopped")
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		logger.Info("grpc server has been stopped gracefully")
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		<-stopped
+		logger.Warn("grpc server has been stopped forcibly", "err", ctx.Err())
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,118 @@
+package grpcwrapper
+
+import (
+	"crypto/tls"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+type grpcServerOptions struct {
+	tlsConfig          *tls.Config
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	serverOptions      []grpc.ServerOption
+
+	// debugging
+	reflection   bool
+	channelz     bool
+	accessLogger AccessLogger
+}
+
+// GrpcServerOption NewGrpcServer 설정 옵션
+type GrpcServerOption func(options *grpcServerOptions)
+
+// WithServerTLS TLS/mTLS 설정 (NewServerTLSConfig 참고)
+func WithServerTLS(config *tls.Config) GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		options.tlsConfig = config
+	}
+}
+
+// WithUnaryInterceptors 등록 순서대로 chain 으로 실행
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		options.unaryInterceptors = append(options.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors 등록 순서대로 chain 으로 실행
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		options.streamInterceptors = append(options.streamInterceptors, interceptors...)
+	}
+}
+
+// WithKeepalive keepalive 파라미터 및 client ping 제한 정책
+func WithKeepalive(params keepalive.ServerParameters, policy keepalive.EnforcementPolicy) GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		options.serverOptions = append(options.serverOptions,
+			grpc.KeepaliveParams(params), grpc.KeepaliveEnforcementPolicy(policy))
+	}
+}
+
+// WithMaxMessageSize 송/수신 최대 메시지 크기 (bytes, 0 이면 grpc 기본값)
+func WithMaxMessageSize(maxRecvSize, maxSendSize int) GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		if maxRecvSize > 0 {
+			options.serverOptions = append(options.serverOptions, grpc.MaxRecvMsgSize(maxRecvSize))
+		}
+		if maxSendSize > 0 {
+			options.serverOptions = append(options.serverOptions, grpc.MaxSendMsgSize(maxSendSize))
+		}
+	}
+}
+
+func WithMaxConcurrentStreams(count uint32) GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		options.serverOptions = append(options.serverOptions, grpc.MaxConcurrentStreams(count))
+	}
+}
+
+// WithServerOptions 그 외 grpc.ServerOption 직접 지정
+func WithServerOptions(serverOptions ...grpc.ServerOption) GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		options.serverOptions = append(options.serverOptions, serverOptions...)
+	}
+}
+
+// WithReflection grpcurl 등에서 서비스를 조회할 수 있도록 server reflection 등록
+func WithReflection() GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		options.reflection = true
+	}
+}
+
+// WithChannelz connection 상태 조회용 channelz 서비스 등록
+func WithChannelz() GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		options.channelz = true
+	}
+}
+
+// WithAccessLog rpc 별 method, peer, status code, latency, 메시지 크기 로깅. logger 가 nil 이면 DefaultAccessLogger
+func WithAccessLog(logger AccessLogger) GrpcServerOption {
+	return func(options *grpcServerOptions) {
+		if logger == nil {
+			logger = DefaultAccessLogger
+		}
+		options.accessLogger = logger
+	}
+}
+
+func (o *grpcServerOptions) build() []grpc.ServerOption {
+	var serverOptions []grpc.ServerOption
+	if o.tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(o.tlsConfig)))
+	}
+	if len(o.unaryInterceptors) > 0 {
+		serverOptions = append(serverOptions, grpc.ChainUnaryInterceptor(o.unaryInterceptors...))
+	}
+	if len(o.streamInterceptors) > 0 {
+		serverOptions = append(serverOptions, grpc.ChainStreamInterceptor(o.streamInterceptors...))
+	}
+	if o.accessLogger != nil {
+		serverOptions = append(serverOptions, grpc.StatsHandler(&accessLogHandler{logger: o.accessLogger}))
+	}
+	return append(serverOptions, o.serverOptions...)
+}
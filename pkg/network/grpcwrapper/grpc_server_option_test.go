@@ -0,0 +1,255 @@
+package grpcwrapper
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerInterceptors(t *testing.T) {
+
+	metrics := NewRequestMetrics()
+	_, addr := startGrpcServer(t, nil, WithUnaryInterceptors(
+		MetricsUnaryInterceptor(metrics.Record),
+		RecoveryUnaryInterceptor(),
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if req.(*healthpb.HealthCheckRequest).Service == "panic" {
+				panic("handler bug")
+			}
+			return handler(ctx, req)
+		}))
+	client := healthpb.NewHealthClient(dialGrpc(t, addr))
+
+	tests := []struct {
+		service string
+		code    codes.Code
+	}{
+		{service: "", code: codes.OK},
+		{service: "panic", code: codes.Internal},
+		{service: "cache", code: codes.NotFound},
+	}
+	for _, test := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: test.service})
+		cancel()
+		if status.Code(err) != test.code {
+			t.Errorf("Check(%q) error = %v, want %v", test.service, err, test.code)
+		}
+	}
+
+	// 등록 순서대로 실행되므로 recover 된 panic 도 metrics 에 기록된다
+	snapshot := metrics.Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("Snapshot() = %v methods, want 1", len(snapshot))
+	}
+	want := map[string]int64{"OK": 1, "Internal": 1, "NotFound": 1}
+	if got := snapshot[0]; got.Method != "/grpc.health.v1.Health/Check" || got.Count != 3 || got.ErrorCount != 2 || !reflect.DeepEqual(got.Codes, want) {
+		t.Errorf("Snapshot() = %+v, want 3 calls with codes %v", got, want)
+	}
+}
+
+func TestServerMaxMessageSize(t *testing.T) {
+
+	_, addr := startGrpcServer(t, nil, WithMaxMessageSize(64, 0))
+	client := healthpb.NewHealthClient(dialGrpc(t, addr))
+
+	tests := []struct {
+		service string
+		code    codes.Code
+	}{
+		{service: "", code: codes.OK},
+		{service: strings.Repeat("x", 100), code: codes.ResourceExhausted},
+	}
+	for _, test := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: test.service})
+		cancel()
+		if status.Code(err) != test.code {
+			t.Errorf("Check(%v bytes) error = %v, want %v", len(test.service), err, test.code)
+		}
+	}
+}
+
+type testCertificates struct {
+	caFile, serverCert, serverKey, clientCert, clientKey string
+}
+
+// writeTestCertificates 테스트용 CA 와 CA 가 서명한 서버 (localhost, 127.0.0.1), client 인증서
+func writeTestCertificates(t *testing.T) *testCertificates {
+	t.Helper()
+	dir := t.TempDir()
+
+	writePem := func(name, blockType string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	newKey := func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	caKey := newKey()
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key := newKey()
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return writePem(name+".crt", "CERTIFICATE", der), writePem(name+".key", "PRIVATE KEY", keyDer)
+	}
+
+	certificates := &testCertificates{caFile: writePem("ca.crt", "CERTIFICATE", caDer)}
+	certificates.serverCert, certificates.serverKey = issue("server", 2, x509.ExtKeyUsageServerAuth)
+	certificates.clientCert, certificates.clientKey = issue("client", 3, x509.ExtKeyUsageClientAuth)
+	return certificates
+}
+
+func TestServerTLS(t *testing.T) {
+
+	certificates := writeTestCertificates(t)
+	serverConfig, err := NewServerTLSConfig(certificates.serverCert, certificates.serverKey, certificates.caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, addr := startGrpcServer(t, nil, WithServerTLS(serverConfig))
+
+	withClientCert, err := NewClientTLSConfig(certificates.caFile, certificates.clientCert, certificates.clientKey, "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutClientCert, err := NewClientTLSConfig(certificates.caFile, "", "", "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options []GrpcClientOption
+		ok      bool
+	}{
+		{name: "mtls", options: []GrpcClientOption{WithClientTLS(withClientCert)}, ok: true},
+		{name: "without client certificate", options: []GrpcClientOption{WithClientTLS(withoutClientCert)}},
+		{name: "insecure", options: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := NewGrpcClient(test.options...)
+			defer func() {
+				_ = client.Close()
+			}()
+			_, err := client.Request(context.Background(), addr, time.Second, nil,
+				func(conn *grpc.ClientConn, ctx context.Context) (interface{}, error) {
+					return healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+				})
+			if (err == nil) != test.ok {
+				t.Errorf("Request() error = %v, want success %v", err, test.ok)
+			}
+		})
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+
+	certificates := writeTestCertificates(t)
+	invalidCA := filepath.Join(t.TempDir(), "invalid.crt")
+	if err := ioutil.WriteFile(invalidCA, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		build func() (*tls.Config, error)
+		want  string
+	}{
+		{
+			name: "missing server key",
+			build: func() (*tls.Config, error) {
+				return NewServerTLSConfig(certificates.serverCert, certificates.serverKey+".missing", "")
+			},
+			want: "load server key pair error",
+		},
+		{
+			name: "invalid client ca",
+			build: func() (*tls.Config, error) {
+				return NewServerTLSConfig(certificates.serverCert, certificates.serverKey, invalidCA)
+			},
+			want: "invalid ca certificate",
+		},
+		{
+			name: "missing ca",
+			build: func() (*tls.Config, error) {
+				return NewClientTLSConfig(invalidCA+".missing", "", "", "localhost")
+			},
+			want: "read ca file error",
+		},
+		{
+			name: "mismatched client key",
+			build: func() (*tls.Config, error) {
+				return NewClientTLSConfig(certificates.caFile, certificates.clientCert, certificates.serverKey, "localhost")
+			},
+			want: "load client key pair error",
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := test.build(); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%v: error = %v, want %q", test.name, err, test.want)
+		}
+	}
+}
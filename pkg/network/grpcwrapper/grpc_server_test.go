@@ -0,0 +1,198 @@
+package grpcwrapper
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startGrpcServer 127.0.0.1 의 임의 port 로 실행한 서버와 주소. 테스트가 끝나면 종료한다
+func startGrpcServer(t *testing.T, setupCallback func(server *grpc.Server), options ...GrpcServerOption) (*GrpcServer, string) {
+	t.Helper()
+	server, err := NewGrpcServer("127.0.0.1:0", setupCallback, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = server.Run()
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	})
+	return server, server.Addr().String()
+}
+
+func dialGrpc(t *testing.T, target string, dialOptions ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.Dial(target, append([]grpc.DialOption{grpc.WithInsecure()}, dialOptions...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestGrpcHealthService(t *testing.T) {
+
+	server, addr := startGrpcServer(t, nil)
+	var failing int32
+	server.HealthRegistry().AddReadinessCheck("database", func(ctx context.Context) error {
+		if atomic.LoadInt32(&failing) == 1 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	client := healthpb.NewHealthClient(dialGrpc(t, addr))
+
+	tests := []struct {
+		name    string
+		setup   func()
+		service string
+		want    healthpb.HealthCheckResponse_ServingStatus
+		code    codes.Code
+	}{
+		{name: "readiness", service: "", want: healthpb.HealthCheckResponse_SERVING},
+		{name: "named check", service: "database", want: healthpb.HealthCheckResponse_SERVING},
+		{name: "unknown service", service: "cache", code: codes.NotFound},
+		{name: "failing readiness", setup: func() { atomic.StoreInt32(&failing, 1) }, service: "", want: healthpb.HealthCheckResponse_NOT_SERVING},
+		{name: "failing named check", service: "database", want: healthpb.HealthCheckResponse_NOT_SERVING},
+		{name: "shutting down", setup: func() { atomic.StoreInt32(&failing, 0); server.HealthRegistry().SetReady(false) }, service: "", want: healthpb.HealthCheckResponse_NOT_SERVING},
+		{name: "named check while shutting down", service: "database", want: healthpb.HealthCheckResponse_NOT_SERVING},
+	}
+
+	for _, test := range tests {
+		if test.setup != nil {
+			test.setup()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		response, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: test.service})
+		cancel()
+		if status.Code(err) != test.code {
+			t.Fatalf("%v: Check() error = %v, want %v", test.name, err, test.code)
+		}
+		if err == nil && response.Status != test.want {
+			t.Errorf("%v: Check() = %v, want %v", test.name, response.Status, test.want)
+		}
+	}
+}
+
+func TestGrpcHealthWatch(t *testing.T) {
+
+	server, addr := startGrpcServer(t, nil)
+	client := healthpb.NewHealthClient(dialGrpc(t, addr))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 현재 상태를 먼저 보내고, 상태가 바뀔 때만 다시 보낸다
+	response, err := stream.Recv()
+	if err != nil || response.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Watch() first = %v, %v, want SERVING", response, err)
+	}
+	server.HealthRegistry().SetReady(false)
+	response, err = stream.Recv()
+	if err != nil || response.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Watch() after SetReady(false) = %v, %v, want NOT_SERVING", response, err)
+	}
+}
+
+// blockingCheck started 로 시작을 알리고 release 가 닫히거나 rpc 가 취소될 때까지 block 하는 점검 항목
+func blockingCheck(started chan<- struct{}, release <-chan struct{}) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestGrpcServerShutdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		release  bool
+		wantErr  error
+		wantCode codes.Code
+	}{
+		// 처리중인 rpc 가 끝날 때까지 기다린다
+		{name: "graceful", timeout: 5 * time.Second, release: true, wantCode: codes.OK},
+		// ctx 가 만료되면 처리중인 rpc 를 강제로 종료한다
+		{name: "forced", timeout: 300 * time.Millisecond, wantErr: context.DeadlineExceeded, wantCode: codes.Unavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			server, err := NewGrpcServer("127.0.0.1:0", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			runErr := make(chan error, 1)
+			go func() {
+				runErr <- server.Run()
+			}()
+
+			started, release := make(chan struct{}, 1), make(chan struct{})
+			defer close(release)
+			server.HealthRegistry().AddReadinessCheck("slow", blockingCheck(started, release))
+
+			client := healthpb.NewHealthClient(dialGrpc(t, server.Addr().String()))
+			callErr := make(chan error, 1)
+			go func() {
+				_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "slow"})
+				callErr <- err
+			}()
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("rpc did not start")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+			shutdownErr := make(chan error, 1)
+			go func() {
+				shutdownErr <- server.Shutdown(ctx)
+			}()
+
+			select {
+			case err := <-shutdownErr:
+				t.Fatalf("Shutdown() = %v before the rpc finished", err)
+			case <-time.After(100 * time.Millisecond):
+			}
+			if server.HealthRegistry().IsReady() {
+				t.Error("IsReady() = true while shutting down")
+			}
+			if test.release {
+				release <- struct{}{}
+			}
+
+			if err := <-shutdownErr; !errors.Is(err, test.wantErr) {
+				t.Errorf("Shutdown() = %v, want %v", err, test.wantErr)
+			}
+			if err := <-callErr; status.Code(err) != test.wantCode {
+				t.Errorf("Check() error = %v, want %v", err, test.wantCode)
+			}
+			if err := <-runErr; err != nil {
+				t.Errorf("Run() = %v, want nil after Shutdown", err)
+			}
+		})
+	}
+}
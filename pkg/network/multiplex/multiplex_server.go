@@ -0,0 +1,136 @@
+package multiplex
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
+	"github.com/hwangtaeseung/neptune-core/pkg/network/grpcwrapper"
+	"github.com/hwangtaeseung/neptune-core/pkg/network/websock"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// logger multiplex package 로거 (logging.Configure("multiplex", ...) 로 설정)
+var logger = logging.For("multiplex")
+
+// drainCheckInterval 처리중인 grpc 요청 종료 확인 주기
+const drainCheckInterval = 100 * time.Millisecond
+
+// MultiplexServer 하나의 port 에서 grpc (HTTP/2) 와 WSServer 의 http, websocket 요청을 함께 처리
+type MultiplexServer struct {
+	server     *http.Server
+	wsServer   *websock.WSServer
+	grpcServer *grpcwrapper.GrpcServer
+	tlsConfig  *tls.Config
+
+	// 처리중인 grpc 요청 수
+	grpcInFlight int64
+}
+
+// NewMultiplexServer grpcServer 는 grpcwrapper.NewGrpcHandlerServer 로 생성한다.
+// tlsConfig 가 nil 이면 평문 (h2c) 으로 동작하며, 두 서버의 health registry 는 wsServer 의 것으로 공유된다
+func NewMultiplexServer(addr string, wsServer *websock.WSServer, grpcServer *grpcwrapper.GrpcServer,
+	tlsConfig *tls.Config) (*MultiplexServer, error) {
+
+	multiplexServer := &MultiplexServer{
+		wsServer:   wsServer,
+		grpcServer: grpcServer,
+		tlsConfig:  tlsConfig,
+	}
+	grpcServer.SetHealthRegistry(wsServer.HealthRegistry())
+
+	multiplexServer.server = &http.Server{
+		Addr:      addr,
+		TLSConfig: tlsConfig,
+	}
+
+	// 평문 HTTP/2 (h2c) 연결도 http 서버 shutdown 시 GOAWAY 를 받도록 같은 http2.Server 사용
+	http2Server := &http2.Server{}
+	if err := http2.ConfigureServer(multiplexServer.server, http2Server); err != nil {
+		return nil, err
+	}
+	multiplexServer.server.Handler = h2c.NewHandler(http.HandlerFunc(multiplexServer.route), http2Server)
+
+	return multiplexServer, nil
+}
+
+func (m *MultiplexServer) route(writer http.ResponseWriter, request *http.Request) {
+	if request.ProtoMajor == 2 && strings.HasPrefix(request.Header.Get("Content-Type"), "application/grpc") {
+		atomic.AddInt64(&m.grpcInFlight, 1)
+		defer atomic.AddInt64(&m.grpcInFlight, -1)
+		m.grpcServer.ServeHTTP(writer, request)
+		return
+	}
+	m.wsServer.Handler().ServeHTTP(writer, request)
+}
+
+// Run listen & serve. Shutdown 이 호출될 때까지 block
+func (m *MultiplexServer) Run() error {
+	listener, err := net.Listen("tcp", m.server.Addr)
+	if err != nil {
+		logger.Error("multiplex server listen error", "err", err)
+		return err
+	}
+	return m.Serve(listener)
+}
+
+// Serve 주어진 listener 로 serve. Shutdown 이 호출될 때까지 block
+func (m *MultiplexServer) Serve(listener net.Listener) error {
+
+	m.wsServer.RunSessions()
+
+	logger.Info("multiplex server has been started", "listen", listener.Addr(), "tls", m.tlsConfig != nil)
+
+	var err error
+	if m.tlsConfig != nil {
+		err = m.server.ServeTLS(listener, "", "")
+	} else {
+		err = m.server.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Info("multiplex server finish", "err", err)
+		return err
+	}
+	return nil
+}
+
+// Shutdown readiness 를 내리고 새 연결을 거부한 후, websocket session 과 처리중인 grpc 요청을 정리.
+// ctx 가 만료되면 남은 grpc 요청은 강제 종료된다
+func (m *MultiplexServer) Shutdown(ctx context.Context) error {
+
+	// readiness off
+	m.wsServer.HealthRegistry().SetReady(false)
+
+	// stop accepting (HTTP/2 연결에는 GOAWAY 전송)
+	httpErr := m.server.Shutdown(ctx)
+
+	// websocket sessions
+	wsErr := m.wsServer.Shutdown(ctx)
+
+	// grpc ServeHTTP 는 GracefulStop 을 지원하지 않으므로 처리중인 요청이 끝나기를 기다린 후 종료 (Stop)
+	for atomic.LoadInt64(&m.grpcInFlight) > 0 {
+		select {
+		case <-ctx.Done():
+			logger.Warn("multiplex server shutdown timed out", "grpc_in_flight", atomic.LoadInt64(&m.grpcInFlight))
+			_ = m.grpcServer.Shutdown(ctx)
+			return ctx.Err()
+		case <-time.After(drainCheckInterval):
+		}
+	}
+	grpcErr := m.grpcServer.Shutdown(ctx)
+
+	logger.Info("multiplex server shutdown gracefully")
+
+	for _, err := range []error{httpErr, wsErr, grpcErr} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
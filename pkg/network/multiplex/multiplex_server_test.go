@@ -0,0 +1,201 @@
+package multiplex
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/hwangtaeseung/neptune-core/pkg/network/grpcwrapper"
+	"github.com/hwangtaeseung/neptune-core/pkg/network/websock"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type multiplexFixture struct {
+	server   *MultiplexServer
+	wsServer *websock.WSServer
+	addr     string
+	served   chan error
+}
+
+// startMultiplexServer health service 만 있는 grpc 서버와 /hello, websocket echo 를 처리하는 WSServer 를 한 port 로 실행
+func startMultiplexServer(t *testing.T) *multiplexFixture {
+	t.Helper()
+
+	wsServer := websock.NewWSServer("", websock.NewWSHandler(func(session *websock.WSSession, message []byte) {
+		session.Send(websocket.TextMessage, message)
+	}, nil), nil, &websock.HttpHandler{
+		Path:   "/hello",
+		Method: http.MethodGet,
+		MessageHandler: func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = writer.Write([]byte("hello " + request.Proto))
+		},
+	})
+	server, err := NewMultiplexServer("127.0.0.1:0", wsServer, grpcwrapper.NewGrpcHandlerServer(nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := &multiplexFixture{server: server, wsServer: wsServer, addr: listener.Addr().String(), served: make(chan error, 1)}
+	go func() {
+		fixture.served <- server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	})
+	return fixture
+}
+
+func (f *multiplexFixture) healthClient(t *testing.T) healthpb.HealthClient {
+	t.Helper()
+	conn, err := grpc.Dial(f.addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return healthpb.NewHealthClient(conn)
+}
+
+func check(client healthpb.HealthClient, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	response, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+	return response.Status, nil
+}
+
+func TestMultiplexServerRouting(t *testing.T) {
+
+	fixture := startMultiplexServer(t)
+	fixture.wsServer.HealthRegistry().AddReadinessCheck("database", func(context.Context) error { return nil })
+
+	// grpc (h2c). health registry 는 WSServer 와 공유한다
+	client := fixture.healthClient(t)
+	for _, service := range []string{"", "database"} {
+		if got, err := check(client, service); err != nil || got != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Check(%q) = %v, %v, want SERVING", service, got, err)
+		}
+	}
+
+	// http
+	for path, want := range map[string]string{"/hello": "hello HTTP/1.1", "/readyz": `"status":"ok"`} {
+		response, err := http.Get("http://" + fixture.addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(response.Body)
+		_ = response.Body.Close()
+		if response.StatusCode != http.StatusOK || !strings.Contains(string(body), want) {
+			t.Errorf("GET %v = %v %q, want %q", path, response.StatusCode, body, want)
+		}
+	}
+
+	// websocket
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+fixture.addr+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if _, message, err := conn.ReadMessage(); err != nil || string(message) != "ping" {
+		t.Errorf("websocket echo = %q, %v, want ping", message, err)
+	}
+}
+
+func TestMultiplexServerShutdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		release bool
+		wantErr error
+	}{
+		// 처리중인 grpc 요청이 끝날 때까지 기다린다
+		{name: "drain", timeout: 5 * time.Second, release: true},
+		// ctx 가 만료되면 남은 grpc 요청을 강제로 종료한다
+		{name: "timeout", timeout: 300 * time.Millisecond, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			fixture := startMultiplexServer(t)
+			started, release := make(chan struct{}, 1), make(chan struct{})
+			defer close(release)
+			fixture.wsServer.HealthRegistry().AddReadinessCheck("slow", func(ctx context.Context) error {
+				started <- struct{}{}
+				select {
+				case <-release:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+
+			client := fixture.healthClient(t)
+			callErr := make(chan error, 1)
+			go func() {
+				_, err := check(client, "slow")
+				callErr <- err
+			}()
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("grpc request did not start")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+			shutdownErr := make(chan error, 1)
+			go func() {
+				shutdownErr <- fixture.server.Shutdown(ctx)
+			}()
+
+			select {
+			case err := <-shutdownErr:
+				t.Fatalf("Shutdown() = %v before the grpc request finished", err)
+			case <-time.After(200 * time.Millisecond):
+			}
+			if fixture.wsServer.HealthRegistry().IsReady() {
+				t.Error("IsReady() = true while shutting down")
+			}
+			// 새 연결은 받지 않는다
+			if response, err := http.Get("http://" + fixture.addr + "/hello"); err == nil {
+				_ = response.Body.Close()
+				t.Error("GET /hello succeeded while shutting down")
+			}
+			if test.release {
+				release <- struct{}{}
+			}
+
+			if err := <-shutdownErr; !errors.Is(err, test.wantErr) {
+				t.Errorf("Shutdown() = %v, want %v", err, test.wantErr)
+			}
+			if err := <-callErr; (err == nil) != test.release {
+				t.Errorf("Check() error = %v, want error %v", err, !test.release)
+			}
+			if err := <-fixture.served; err != nil {
+				t.Errorf("Serve() = %v, want nil after Shutdown", err)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package websock
 
 import (
 	"github.com/gorilla/websocket"
-	"log"
 	"net/url"
 )
 
@@ -30,7 +29,7 @@ func (w *WSClient) Connect(scheme string, address string, path string) error {
 		if w.OnError != nil {
 			w.OnError(err)
 		}
-		log.Printf("connection error : %+v\n", err)
+		logger.Error("websocket connection error", "url", u.String(), "err", err)
 		return err
 	}
 	w.client = client
@@ -56,7 +55,7 @@ func (w *WSClient) Connect(scheme string, address string, path string) error {
 func (w *WSClient) processToRead() {
 
 	defer func() {
-		log.Printf("exit goroutine for reading message")
+		logger.Debug("exit goroutine for reading message")
 		close(w.done)
 	}()
 
@@ -65,9 +64,9 @@ func (w *WSClient) processToRead() {
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err,
 				websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseNoStatusReceived) {
-				log.Printf("websocket client closed : %v\n", err)
+				logger.Info("websocket client closed", "err", err)
 			} else {
-				log.Printf("read error : %+v\n", err)
+				logger.Warn("websocket read error", "err", err)
 			}
 
 			// disconnect event
@@ -86,7 +85,7 @@ func (w *WSClient) processToRead() {
 func (w *WSClient) processToWrite() {
 
 	defer func() {
-		log.Printf("exit goroutine for writing message")
+		logger.Debug("exit goroutine for writing message")
 		close(w.messageQueueToWrite)
 	}()
 
@@ -97,12 +96,12 @@ func (w *WSClient) processToWrite() {
 		case message, ok := <-w.messageQueueToWrite:
 			if !ok {
 				_ = w.client.WriteMessage(websocket.CloseMessage, []byte{})
-				log.Printf("text message queue channel is closed")
+				logger.Debug("text message queue channel is closed")
 				return
 			}
 
 			if err := w.client.WriteMessage(message.MsgType, message.Message); err != nil {
-				log.Printf("write error : %+v\n", err)
+				logger.Warn("websocket write error", "err", err)
 				return
 			}
 			// call write event
@@ -116,7 +115,7 @@ func (w *WSClient) processToWrite() {
 func (w *WSClient) Disconnect() {
 	if err := w.client.WriteMessage(websocket.CloseMessage,
 		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
-		log.Printf("disconnect error : %+v\n", err)
+		logger.Warn("websocket disconnect error", "err", err)
 	}
 }
 
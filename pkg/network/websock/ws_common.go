@@ -2,10 +2,14 @@ package websock
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/gorilla/websocket"
-	"log"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
 )
 
+// logger websock package 로거 (logging.Configure("websock", ...) 로 설정)
+var logger = logging.For("websock")
+
 type Message struct {
 	MsgType int
 	Message []byte
@@ -19,10 +23,23 @@ func send(buffer chan *Message, msgType int, message []byte) {
 }
 
 func sendJson(buffer chan *Message, message interface{}) {
-	if jsonMessage, err := json.Marshal(&message); err != nil {
-		log.Printf("invalid message in send object : %+v\n", message)
-	} else {
-		log.Printf("message sent to client : %v\n", string(jsonMessage))
-		send(buffer, websocket.TextMessage, jsonMessage)
+	if jsonMessage := encodeJson(message); jsonMessage != nil {
+		buffer <- jsonMessage
+	}
+}
+
+// encodeJson message 를 JSON text frame 으로 변환. 실패하면 로그를 남기고 nil 반환
+func encodeJson(message interface{}) *Message {
+	jsonMessage, err := json.Marshal(&message)
+	if err != nil {
+		logger.Error("invalid message in send object", "message", fmt.Sprintf("%+v", message), "err", err)
+		return nil
+	}
+	if logger.Enabled(logging.LevelDebug) {
+		logger.Debug("message sent to client", "message", string(jsonMessage))
+	}
+	return &Message{
+		MsgType: websocket.TextMessage,
+		Message: jsonMessage,
 	}
 }
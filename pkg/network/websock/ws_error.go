@@ -0,0 +1,58 @@
+package websock
+
+import (
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"net/http"
+)
+
+// ErrorMessage websocket 으로 전송하는 에러 frame
+type ErrorMessage struct {
+	ProtocolId string               `json:"protocol_id"`
+	RequestId  string               `json:"request_id,omitempty"`
+	Error      *common.NeptuneError `json:"error"`
+}
+
+// NewErrorMessage err 는 common.AsNeptuneError 로 변환된다
+func NewErrorMessage(protocolId string, requestId string, err error) *ErrorMessage {
+	return &ErrorMessage{
+		ProtocolId: protocolId,
+		RequestId:  requestId,
+		Error:      common.AsNeptuneError(err),
+	}
+}
+
+// SendError 요청 (protocolId, requestId) 에 대한 에러 frame 전송
+func (w *WSSession) SendError(protocolId string, requestId string, err error) {
+	w.SendJson(NewErrorMessage(protocolId, requestId, err))
+}
+
+// HttpStatusOf err 의 category 에 해당하는 HTTP status (nil 이면 200)
+func HttpStatusOf(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	switch common.AsNeptuneError(err).Category() {
+	case common.CategoryInvalidArgument:
+		return http.StatusBadRequest
+	case common.CategoryNotFound:
+		return http.StatusNotFound
+	case common.CategoryTimeout:
+		return http.StatusGatewayTimeout
+	case common.CategoryCanceled, common.CategoryUnavailable:
+		return http.StatusServiceUnavailable
+	case common.CategoryResourceExhausted:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteHttpError HttpHandler 에서 에러 응답. body 는 {"error":{"code":...,"message":...}}
+func WriteHttpError(writer http.ResponseWriter, err error) {
+	neptuneError := common.AsNeptuneError(err)
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(HttpStatusOf(err))
+	if jsonBytes, err := common.ToJson(map[string]interface{}{"error": neptuneError}); err == nil {
+		_, _ = writer.Write(jsonBytes)
+	}
+}
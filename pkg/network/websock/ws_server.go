@@ -4,9 +4,10 @@ import (
 	"context"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
-	"log"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
 	"net/http"
 	"reflect"
+	"sync"
 	"time"
 )
 
@@ -16,7 +17,7 @@ type WSServer struct {
 	// http server
 	server *http.Server
 
-	// sessions
+	// sessions (processSession goroutine 에서만 접근. false 는 send 가 닫혀서 read goroutine 종료를 기다리는 session)
 	sessions map[*WSSession]bool
 
 	// Inbound messages from the sessions.
@@ -25,12 +26,31 @@ type WSServer struct {
 	// Register requests from the sessions.
 	register chan *WSSession
 
-	// Unregister requests from sessions.
+	// Unregister requests from sessions. (read goroutine 이 종료될 때 전송)
 	unregister chan *WSSession
 
+	// session 종료 요청 (WSSession.Close)
+	disconnect chan *WSSession
+
+	// session 수 조회 (SessionCount)
+	count chan chan int
+
+	// Shutdown 이 닫는 channel. shutdown 은 모든 session 종료, forceClose 는 남은 connection 을 강제로 닫는다
+	shutdown     chan struct{}
+	forceClose   chan struct{}
+	shutdownOnce sync.Once
+	forceOnce    sync.Once
+
+	// processSession 이 종료되면 닫힌다 (모든 read goroutine 종료 후)
+	done         chan struct{}
+	sessionsOnce sync.Once
+
 	// ws handler
 	wsHandler *WSHandler
 
+	// health checks for /healthz, /readyz
+	health *common.HealthRegistry
+
 	// on connect
 	OnConnect func(*WSSession)
 
@@ -58,13 +78,27 @@ func NewWSServer(addr string, wsHandler *WSHandler,
 		broadcast:  make(chan *Message),
 		register:   make(chan *WSSession),
 		unregister: make(chan *WSSession),
+		disconnect: make(chan *WSSession),
+		count:      make(chan chan int),
+		shutdown:   make(chan struct{}),
+		forceClose: make(chan struct{}),
+		done:       make(chan struct{}),
 		sessions:   make(map[*WSSession]bool),
 		wsHandler:  wsHandler,
+		health:     common.NewHealthRegistry(),
 	}
 
 	// router for http server
 	router := mux.NewRouter()
 
+	// health check handler
+	router.HandleFunc("/healthz", func(writer http.ResponseWriter, request *http.Request) {
+		writeHealthReport(writer, wsServer.health.CheckLiveness(request.Context()))
+	}).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", func(writer http.ResponseWriter, request *http.Request) {
+		writeHealthReport(writer, wsServer.health.CheckReadiness(request.Context()))
+	}).Methods(http.MethodGet)
+
 	// set up http handler
 	if httpHandlers != nil {
 		for _, httpHandler := range httpHandlers {
@@ -92,6 +126,37 @@ func NewWSServer(addr string, wsHandler *WSHandler,
 	return wsServer
 }
 
+// SetHealthRegistry GrpcServer 등과 상태 점검 목록을 공유할 때 사용
+func (s *WSServer) SetHealthRegistry(registry *common.HealthRegistry) *WSServer {
+	s.health = registry
+	return s
+}
+
+func (s *WSServer) HealthRegistry() *common.HealthRegistry {
+	return s.health
+}
+
+// Broadcast 연결된 모든 session 에 전송. 종료된 server 에서는 무시
+func (s *WSServer) Broadcast(msgType int, message []byte) {
+	s.TryBroadcast(&Message{MsgType: msgType, Message: message})
+}
+
+func (s *WSServer) BroadcastJson(message interface{}) {
+	if jsonMessage := encodeJson(message); jsonMessage != nil {
+		s.TryBroadcast(jsonMessage)
+	}
+}
+
+// TryBroadcast 연결된 모든 session 에 전송. server 가 종료되었으면 false (panic 하지 않음)
+func (s *WSServer) TryBroadcast(message *Message) bool {
+	select {
+	case s.broadcast <- message:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
 func (s *WSServer) MsgHandler(session *WSSession, messageType int, message []byte) {
 	switch messageType {
 	case websocket.TextMessage:
@@ -117,96 +182,185 @@ func (s *WSServer) Run() {
 	})
 }
 
+// Handler http/websocket 라우터 (multiplex.MultiplexServer 등 외부 http 서버에 연결할 때 사용)
+func (s *WSServer) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// RunSessions listen 하지 않고 session 처리만 시작 (Handler 를 외부 http 서버에 연결한 경우)
+func (s *WSServer) RunSessions() {
+	s.startSessions()
+}
+
+func (s *WSServer) startSessions() {
+	s.sessionsOnce.Do(func() {
+		go s.processSession()
+	})
+}
+
 func (s *WSServer) run(callback func() error) {
 
 	// run to process websocket client
-	go s.processSession()
+	s.startSessions()
 
 	// listen & serve
 	go func() {
 		if err := callback(); err != nil {
 			if reflect.TypeOf(err) != reflect.TypeOf(http.ErrServerClosed) {
-				log.Printf("network finish : %v\n", err)
+				logger.Error("websocket server finish", "err", err)
 				panic(err)
 			}
 		}
 	}()
 
-	log.Printf("network hans been started... (listen=%v)\n", s.server.Addr)
+	logger.Info("websocket server has been started", "listen", s.server.Addr)
 }
 
 func (s *WSServer) Stop() *WSServer {
+	_ = s.Shutdown(context.Background())
+	return s
+}
 
-	defer func() {
-		close(s.broadcast)
-		close(s.unregister)
-		close(s.register)
-	}()
-
-	// clear sessions map
-	for client := range s.sessions {
-		s.unregister <- client
-		log.Printf("unregister client : %v\n", client)
+// SessionCount 연결된 session 수 (종료 후에는 0)
+func (s *WSServer) SessionCount() int {
+	reply := make(chan int, 1)
+	select {
+	case s.count <- reply:
+		return <-reply
+	case <-s.done:
+		return 0
 	}
+}
+
+// Shutdown 모든 session 에 close frame 을 보내고 read goroutine 이 모두 종료되면 http 서버를 종료.
+// ctx 가 만료되면 남은 connection 을 강제로 닫고 read goroutine 종료를 기다린 후 반환한다
+func (s *WSServer) Shutdown(ctx context.Context) error {
+
+	// readiness off
+	s.health.SetReady(false)
+
+	// session 처리 goroutine 이 없으면 시작 (session 이 없으므로 바로 종료된다)
+	s.startSessions()
+	s.shutdownOnce.Do(func() {
+		close(s.shutdown)
+	})
 
 	// wait for...
-	sessionCount := len(s.sessions)
-	for sessionCount != 0 {
-		log.Printf("network is terminating... (session count : %v)\n", sessionCount)
-		time.Sleep(time.Second)
-		sessionCount = len(s.sessions)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for waiting := true; waiting; {
+		select {
+		case <-s.done:
+			waiting = false
+		case <-ticker.C:
+			logger.Info("websocket server is terminating", "sessions", s.SessionCount())
+		case <-ctx.Done():
+			logger.Warn("websocket server shutdown timed out", "sessions", s.SessionCount())
+			s.forceOnce.Do(func() {
+				close(s.forceClose)
+			})
+			<-s.done
+			_ = s.server.Close()
+			return ctx.Err()
+		}
 	}
 
 	// shutdown http network
-	_ = s.server.Shutdown(context.Background())
+	if err := s.server.Shutdown(ctx); err != nil {
+		logger.Error("websocket server shutdown error", "err", err)
+		return err
+	}
 
-	log.Printf("network shutdown gracefully")
+	logger.Info("websocket server shutdown gracefully")
 
-	return s
+	return nil
 }
 
+// processSession sessions 를 관리하는 유일한 goroutine. Shutdown 후 모든 session 의 read goroutine 이 종료되면 done 을 닫고 반환
 func (s *WSServer) processSession() {
 
+	defer close(s.done)
+
+	shutdown, forceClose := s.shutdown, s.forceClose
+	closing := false
+
 	for {
 		select {
-		case client, ok := <-s.register:
-			if !ok {
-				log.Printf("register channel closed")
-				return
-			}
-			s.sessions[client] = true
-			log.Printf("session has been created. count of session : %v\n", len(s.sessions))
-
-		case session, ok := <-s.unregister:
-			if !ok {
-				log.Printf("unregister channel closed")
-				return
+		case session := <-s.register:
+			s.sessions[session] = true
+			if closing {
+				s.closeSession(session)
 			}
+			logger.Info("session has been created", "sessions", len(s.sessions))
 
-			// remove session object from map
+		case session := <-s.unregister:
+			// read goroutine 종료. remove session object from map
 			if _, ok := s.sessions[session]; ok {
 				// call disconnect handler
 				if s.OnDisconnect != nil {
 					s.OnDisconnect(session)
 				}
+				s.closeSession(session)
 				delete(s.sessions, session)
-				close(session.send)
 			}
-			log.Printf("session has been destroyed. count of session : %v\n", len(s.sessions))
+			logger.Info("session has been destroyed", "sessions", len(s.sessions))
 
-		case message, ok := <-s.broadcast:
-			if !ok {
-				log.Printf("broadcast channel closed")
-				return
-			}
-			for session := range s.sessions {
+		case session := <-s.disconnect:
+			// write goroutine 이 close frame 을 보내고 connection 을 닫으면 read goroutine 이 unregister 한다
+			s.closeSession(session)
+
+		case message := <-s.broadcast:
+			for session, open := range s.sessions {
+				if !open {
+					continue
+				}
 				select {
 				case session.send <- message:
 				default:
-					close(session.send)
-					delete(s.sessions, session)
+					s.closeSession(session)
 				}
 			}
+
+		case reply := <-s.count:
+			reply <- len(s.sessions)
+
+		case <-shutdown:
+			shutdown, closing = nil, true
+			for session := range s.sessions {
+				logger.Debug("unregister client", "remote", session.Conn.RemoteAddr())
+				s.closeSession(session)
+			}
+
+		case <-forceClose:
+			forceClose = nil
+			for session := range s.sessions {
+				_ = session.Conn.Close()
+			}
+		}
+
+		if closing && len(s.sessions) == 0 {
+			logger.Debug("all sessions have been closed")
+			return
 		}
 	}
 }
+
+// closeSession send 를 닫아서 write goroutine 을 종료 (processSession 에서만 호출)
+func (s *WSServer) closeSession(session *WSSession) {
+	if s.sessions[session] {
+		s.sessions[session] = false
+		session.closeSend()
+	}
+}
+
+func writeHealthReport(writer http.ResponseWriter, report *common.HealthReport) {
+	writer.Header().Set("Content-Type", "application/json")
+	if report.Healthy() {
+		writer.WriteHeader(http.StatusOK)
+	} else {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if jsonBytes, err := common.ToJson(report); err == nil {
+		_, _ = writer.Write(jsonBytes)
+	}
+}
@@ -0,0 +1,164 @@
+package websock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getHealth(t *testing.T, url string) (int, *common.HealthReport) {
+	t.Helper()
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	report := &common.HealthReport{}
+	if err := json.NewDecoder(response.Body).Decode(report); err != nil {
+		t.Fatal(err)
+	}
+	return response.StatusCode, report
+}
+
+func TestWSServerHealth(t *testing.T) {
+
+	wsServer := NewWSServer("", NewWSHandler(nil, nil), nil)
+	httpServer := httptest.NewServer(wsServer.Handler())
+	defer httpServer.Close()
+
+	registry := wsServer.HealthRegistry()
+	tests := []struct {
+		name   string
+		setup  func()
+		path   string
+		status int
+		checks map[string]string
+	}{
+		{name: "liveness", path: "/healthz", status: http.StatusOK, checks: map[string]string{}},
+		{name: "readiness", path: "/readyz", status: http.StatusOK, checks: map[string]string{}},
+		{
+			name: "failing liveness",
+			setup: func() {
+				registry.AddLivenessCheck("disk", func(context.Context) error { return errors.New("disk full") })
+			},
+			path: "/healthz", status: http.StatusServiceUnavailable, checks: map[string]string{"disk": "disk full"},
+		},
+		{
+			// liveness 항목은 readiness 에 영향을 주지 않는다
+			name: "readiness ignores liveness", path: "/readyz", status: http.StatusOK, checks: map[string]string{},
+		},
+		{
+			name: "shutting down",
+			setup: func() {
+				registry.RemoveCheck("disk")
+				registry.AddReadinessCheck("database", func(context.Context) error { return nil })
+				registry.SetReady(false)
+			},
+			path: "/readyz", status: http.StatusServiceUnavailable,
+			checks: map[string]string{"database": common.HealthStatusOk, "shutdown": "server is shutting down"},
+		},
+		{name: "liveness while shutting down", path: "/healthz", status: http.StatusOK, checks: map[string]string{}},
+	}
+
+	for _, test := range tests {
+		if test.setup != nil {
+			test.setup()
+		}
+		statusCode, report := getHealth(t, httpServer.URL+test.path)
+		if statusCode != test.status {
+			t.Errorf("%v: status = %v, want %v", test.name, statusCode, test.status)
+		}
+		if len(report.Checks) != len(test.checks) {
+			t.Errorf("%v: checks = %v, want %v", test.name, report.Checks, test.checks)
+		}
+		for name, want := range test.checks {
+			if report.Checks[name] != want {
+				t.Errorf("%v: checks[%v] = %q, want %q", test.name, name, report.Checks[name], want)
+			}
+		}
+	}
+}
+
+func dialWS(t *testing.T, httpServer *httptest.Server) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func waitSessionCount(t *testing.T, wsServer *WSServer, count int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); wsServer.SessionCount() != count; {
+		if time.Now().After(deadline) {
+			t.Fatalf("SessionCount() = %v, want %v", wsServer.SessionCount(), count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWSServerShutdown(t *testing.T) {
+
+	wsServer := NewWSServer("", NewWSHandler(nil, nil), nil)
+	disconnected := make(chan *WSSession, 2)
+	wsServer.OnDisconnect = func(session *WSSession) {
+		disconnected <- session
+	}
+	wsServer.RunSessions()
+	httpServer := httptest.NewServer(wsServer.Handler())
+	defer httpServer.Close()
+
+	conns := []*websocket.Conn{dialWS(t, httpServer), dialWS(t, httpServer)}
+	waitSessionCount(t, wsServer, 2)
+
+	// 읽는 client 는 close frame 에 응답하므로 모든 session 이 정리된 후 반환된다
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- wsServer.Shutdown(ctx)
+	}()
+	for _, conn := range conns {
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNoStatusReceived) {
+			t.Errorf("ReadMessage() error = %v, want close frame", err)
+		}
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+
+	if len(disconnected) != 2 {
+		t.Errorf("OnDisconnect called %v times, want 2", len(disconnected))
+	}
+	if wsServer.HealthRegistry().IsReady() {
+		t.Error("IsReady() = true after Shutdown")
+	}
+	if wsServer.SessionCount() != 0 {
+		t.Errorf("SessionCount() = %v after Shutdown", wsServer.SessionCount())
+	}
+
+	// 종료 후의 전송과 연결은 panic 없이 무시된다
+	if wsServer.TryBroadcast(&Message{MsgType: websocket.TextMessage, Message: []byte("late")}) {
+		t.Error("TryBroadcast() = true after Shutdown")
+	}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	if err == nil {
+		if _, _, err := conn.ReadMessage(); err == nil {
+			t.Error("connection after Shutdown is still open")
+		}
+		_ = conn.Close()
+	}
+}
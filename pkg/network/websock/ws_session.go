@@ -2,9 +2,10 @@ package websock
 
 import (
 	"bytes"
+	"context"
 	"github.com/gorilla/websocket"
-	"log"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -44,14 +45,25 @@ type WSSession struct {
 
 	// user context
 	UserContext interface{}
+
+	// cancelled when the session is unregistered
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// send 를 닫는 동안 TrySend 를 막는다 (closeSend)
+	sendMutex  sync.RWMutex
+	sendClosed bool
 }
 
 func (w *WSSession) processToRead() {
 
 	defer func() {
-		w.Server.unregister <- w
+		select {
+		case w.Server.unregister <- w:
+		case <-w.Server.done:
+		}
 		//_ = w.Conn.Close()
-		log.Printf("client read go routine stop..")
+		logger.Debug("client read goroutine stop")
 	}()
 
 	w.Conn.SetReadLimit(maxMessageSize)
@@ -66,9 +78,9 @@ func (w *WSSession) processToRead() {
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err,
 				websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseNoStatusReceived) {
-				log.Printf("websocket client close ==> %+v\n", err)
+				logger.Info("websocket client close", "err", err)
 			} else {
-				log.Printf("websock client read error ==> %+v", err)
+				logger.Warn("websocket client read error", "err", err)
 			}
 			return
 		}
@@ -88,7 +100,7 @@ func (w *WSSession) processToWrite() {
 	defer func() {
 		ticker.Stop()
 		_ = w.Conn.Close()
-		log.Printf("client write go routine stop..")
+		logger.Debug("client write goroutine stop")
 	}()
 
 	for {
@@ -98,22 +110,22 @@ func (w *WSSession) processToWrite() {
 			if !ok {
 				err := w.Conn.WriteMessage(websocket.CloseMessage, []byte{})
 				if err != nil {
-					log.Printf("websocket write error : %v\n", err)
+					logger.Warn("websocket write error", "err", err)
 				}
-				log.Println("websocket client send channel closed")
+				logger.Debug("websocket client send channel closed")
 				return
 			}
 
 			err := w.Conn.WriteMessage(buffer.MsgType, buffer.Message)
 			if err != nil {
-				log.Printf("write close error : %v\n", err)
+				logger.Warn("write close error", "err", err)
 				return
 			}
 
 		case <-ticker.C:
 			_ = w.Conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := w.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				log.Printf("tick write error : %v\n", err)
+				logger.Warn("ping write error", "err", err)
 				return
 			}
 		}
@@ -121,45 +133,93 @@ func (w *WSSession) processToWrite() {
 }
 
 func (w *WSSession) SendString(message string)  {
-	send(w.send, websocket.TextMessage, []byte(message))
+	w.Send(websocket.TextMessage, []byte(message))
 }
 
 func (w *WSSession) SendMessage(message *Message)  {
-	send(w.send, message.MsgType, message.Message)
+	w.Send(message.MsgType, message.Message)
 }
 
+// Send 종료된 session 에서는 무시 (TrySend 참고)
 func (w *WSSession) Send(msgType int, message []byte) {
-	send(w.send, msgType, message)
+	w.TrySend(&Message{MsgType: msgType, Message: message})
 }
 
 func (w *WSSession) SendJson(message interface{}) {
-	sendJson(w.send, message)
+	if jsonMessage := encodeJson(message); jsonMessage != nil {
+		w.TrySend(jsonMessage)
+	}
+}
+
+// TrySend 전송 queue 에 추가. session 이 종료되었거나 queue 를 기다리는 중에 종료되면 false (panic 하지 않음)
+func (w *WSSession) TrySend(message *Message) bool {
+	w.sendMutex.RLock()
+	defer w.sendMutex.RUnlock()
+	if w.sendClosed {
+		return false
+	}
+	select {
+	case w.send <- message:
+		return true
+	case <-w.ctx.Done():
+		return false
+	}
 }
 
+// closeSend ctx 를 먼저 취소해서 대기중인 TrySend 를 깨운 후, 전송중인 goroutine 이 없을 때 send 를 닫는다
+func (w *WSSession) closeSend() {
+	w.cancel()
+	w.sendMutex.Lock()
+	defer w.sendMutex.Unlock()
+	if !w.sendClosed {
+		w.sendClosed = true
+		close(w.send)
+	}
+}
+
+// Context session 이 종료되면 취소되는 context
+func (w *WSSession) Context() context.Context {
+	return w.ctx
+}
+
+// Close close frame 을 보내고 연결을 종료 (OnDisconnect 는 read goroutine 이 종료될 때 호출)
 func (w *WSSession) Close() {
-	w.Server.unregister <- w
+	select {
+	case w.Server.disconnect <- w:
+	case <-w.Server.done:
+	}
 }
 
 func runWSSession(server *WSServer, responseWriter http.ResponseWriter, request *http.Request) {
 
 	connection, err := upGrader.Upgrade(responseWriter, request, nil)
 	if err != nil {
-		log.Printf("upgrade error : %v\n", err)
+		logger.Error("websocket upgrade error", "err", err)
 		return
 	}
 
 	// create client
+	ctx, cancel := context.WithCancel(context.Background())
 	client := &WSSession{
 		Server: server,
 		Conn:   connection,
 		send:   make(chan *Message, 256),
+		ctx:    ctx,
+		cancel: cancel,
 	}
 
-	// register client
-	server.register <- client
+	// register client (종료된 server 는 연결을 받지 않음)
+	select {
+	case server.register <- client:
+	case <-server.done:
+		cancel()
+		_ = connection.Close()
+		logger.Warn("websocket server has been shut down", "remote", connection.RemoteAddr())
+		return
+	}
 
 	// call connect handler
-	if server.OnDisconnect != nil {
+	if server.OnConnect != nil {
 		server.OnConnect(client)
 	}
 
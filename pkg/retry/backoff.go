@@ -0,0 +1,102 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Backoff attempt (1 부터) 번째 시도가 실패한 뒤 다음 시도까지의 대기 시간. previous 는 직전 대기 시간 (처음은 0)
+type Backoff interface {
+	Next(attempt int, previous time.Duration) time.Duration
+}
+
+// ConstantBackoff 항상 Delay 만큼 대기 (기존 RetryWrapper 동작)
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+func (b *ConstantBackoff) Next(int, time.Duration) time.Duration {
+	return b.Delay
+}
+
+// ExponentialBackoff Initial * Multiplier^(attempt-1) 만큼 대기 (최대 Max).
+// Jitter (0~1) 비율만큼 대기 시간을 무작위로 줄여 동시에 재시도하는 것을 피한다
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+func NewExponentialBackoff(initial time.Duration, max time.Duration) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		Initial:    initial,
+		Max:        max,
+		Multiplier: 2,
+		Jitter:     0.2,
+	}
+}
+
+// Next Max 가 0 이어도 time.Duration 범위를 넘지 않는다
+func (b *ExponentialBackoff) Next(attempt int, _ time.Duration) time.Duration {
+	if b.Initial <= 0 {
+		return 0
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(attempt-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	// +Inf 에 jitter 를 적용하면 NaN 이므로 먼저 제한
+	delay = math.Min(delay, float64(math.MaxInt64))
+	if b.Jitter > 0 {
+		delay -= delay * math.Min(b.Jitter, 1) * random()
+	}
+	return toDuration(delay)
+}
+
+// DecorrelatedJitterBackoff Base ~ 직전 대기 시간 * 3 사이의 무작위 값만큼 대기 (최대 Max)
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func NewDecorrelatedJitterBackoff(base time.Duration, max time.Duration) *DecorrelatedJitterBackoff {
+	return &DecorrelatedJitterBackoff{Base: base, Max: max}
+}
+
+func (b *DecorrelatedJitterBackoff) Next(_ int, previous time.Duration) time.Duration {
+	if previous < b.Base {
+		previous = b.Base
+	}
+	upper := float64(previous) * 3
+	delay := float64(b.Base) + (upper-float64(b.Base))*random()
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	return toDuration(delay)
+}
+
+// toDuration float 로 계산한 대기 시간을 overflow 없이 변환 (float64(math.MaxInt64) 는 int64 범위를 넘는다)
+func toDuration(delay float64) time.Duration {
+	if delay >= float64(math.MaxInt64) {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+var (
+	randomMutex  sync.Mutex
+	randomSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func random() float64 {
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+	return randomSource.Float64()
+}
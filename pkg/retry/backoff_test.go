@@ -0,0 +1,89 @@
+package retry
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+
+	backoff := &ExponentialBackoff{Initial: 100 * time.Millisecond, Max: 500 * time.Millisecond, Multiplier: 2}
+	want := []time.Duration{100, 200, 400, 500, 500}
+	for index, delay := range want {
+		if got := backoff.Next(index+1, 0); got != delay*time.Millisecond {
+			t.Errorf("Next(%v) = %v, want %v", index+1, got, delay*time.Millisecond)
+		}
+	}
+
+	// jitter 는 대기 시간을 Jitter 비율 이내로 줄인다
+	jittered := NewExponentialBackoff(100*time.Millisecond, time.Second)
+	for attempt := 1; attempt <= 5; attempt++ {
+		upper := 100 * time.Millisecond << (attempt - 1)
+		if upper > time.Second {
+			upper = time.Second
+		}
+		lower := time.Duration(float64(upper) * (1 - jittered.Jitter))
+		for count := 0; count < 100; count++ {
+			if got := jittered.Next(attempt, 0); got < lower || got > upper {
+				t.Fatalf("Next(%v) = %v, want %v ~ %v", attempt, got, lower, upper)
+			}
+		}
+	}
+}
+
+func TestExponentialBackoffOverflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff *ExponentialBackoff
+		attempt int
+		want    time.Duration
+	}{
+		{name: "no max", backoff: &ExponentialBackoff{Initial: time.Second, Multiplier: 2}, attempt: 100, want: math.MaxInt64},
+		{name: "infinite", backoff: &ExponentialBackoff{Initial: time.Second, Multiplier: 2}, attempt: 2000, want: math.MaxInt64},
+		{name: "zero initial", backoff: &ExponentialBackoff{Multiplier: 2}, attempt: 2000, want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.backoff.Next(test.attempt, 0); got != test.want {
+				t.Errorf("Next(%v) = %v, want %v", test.attempt, got, test.want)
+			}
+		})
+	}
+
+	// jitter 를 적용해도 음수나 NaN 이 되지 않는다
+	jittered := &ExponentialBackoff{Initial: time.Second, Multiplier: 2, Jitter: 0.5}
+	if got := jittered.Next(2000, 0); got < math.MaxInt64/2 {
+		t.Errorf("Next(2000) with jitter = %v", got)
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+
+	backoff := NewDecorrelatedJitterBackoff(100*time.Millisecond, time.Second)
+	var previous time.Duration
+	for attempt := 1; attempt <= 100; attempt++ {
+		upper := previous * 3
+		if upper < 3*backoff.Base {
+			upper = 3 * backoff.Base
+		}
+		if upper > backoff.Max {
+			upper = backoff.Max
+		}
+		delay := backoff.Next(attempt, previous)
+		if delay < backoff.Base || delay > upper {
+			t.Fatalf("Next(%v, %v) = %v, want %v ~ %v", attempt, previous, delay, backoff.Base, upper)
+		}
+		previous = delay
+	}
+}
+
+func TestConstantBackoff(t *testing.T) {
+	backoff := &ConstantBackoff{Delay: time.Second}
+	for attempt := 1; attempt <= 3; attempt++ {
+		if got := backoff.Next(attempt, time.Duration(attempt)*time.Minute); got != time.Second {
+			t.Errorf("Next(%v) = %v, want 1s", attempt, got)
+		}
+	}
+}
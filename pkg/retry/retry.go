@@ -0,0 +1,180 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"github.com/hwangtaeseung/neptune-core/pkg/logging"
+	"time"
+)
+
+// logger retry package 로거 (logging.Configure("retry", ...) 로 설정)
+var logger = logging.For("retry")
+
+// Attempt 한번의 시도 결과 (Policy.OnAttempt 로 전달)
+type Attempt struct {
+	// 1 부터 시작
+	Number int
+	Err    error
+
+	// 다음 시도까지 대기 시간
+	Delay time.Duration
+
+	// 성공했거나 더 이상 시도하지 않음
+	Final bool
+
+	// 첫 시도부터 경과 시간
+	Elapsed time.Duration
+}
+
+// Policy 재시도 정책
+type Policy struct {
+	// 최대 시도 횟수 (0 이하면 common.DefaultMaxRetryCount)
+	MaxAttempts int
+
+	// 첫 시도부터 이 시간이 지나면 더 이상 시도하지 않음 (0 이면 제한 없음)
+	MaxElapsedTime time.Duration
+
+	// nil 이면 1초 ConstantBackoff
+	Backoff Backoff
+
+	// 재시도할 에러인지 판단 (nil 이면 Retryable)
+	Retryable func(err error) bool
+
+	// 매 시도 후 호출 (로깅, metric 수집)
+	OnAttempt func(attempt *Attempt)
+}
+
+// DefaultPolicy 1초부터 2배씩 (최대 30초) 증가하는 대기로 common.DefaultMaxRetryCount 번 시도
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MaxAttempts: common.DefaultMaxRetryCount,
+		Backoff:     NewExponentialBackoff(time.Second, 30*time.Second),
+	}
+}
+
+// ConstantPolicy delay 간격으로 common.DefaultMaxRetryCount 번 시도 (RetryWrapper 와 같은 대기 시간)
+func ConstantPolicy(delay time.Duration) *Policy {
+	return &Policy{
+		MaxAttempts: common.DefaultMaxRetryCount,
+		Backoff:     &ConstantBackoff{Delay: delay},
+	}
+}
+
+// LogAttempt 실패한 시도를 로그로 남기는 OnAttempt
+func LogAttempt(name string) func(attempt *Attempt) {
+	return func(attempt *Attempt) {
+		if attempt.Err == nil {
+			return
+		}
+		if !attempt.Final {
+			logger.Warn("operation failed. retry", "operation", name, "delay", attempt.Delay, "attempt", attempt.Number, "err", attempt.Err)
+		} else {
+			logger.Error("operation failed finally", "operation", name, "attempt", attempt.Number, "elapsed", attempt.Elapsed, "err", attempt.Err)
+		}
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string {
+	return p.err.Error()
+}
+
+func (p *permanentError) Unwrap() error {
+	return p.err
+}
+
+// Permanent err 를 재시도하지 않도록 표시. Do 는 감싸기 전의 err 를 반환한다
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Retryable 기본 재시도 판단. Permanent 는 재시도하지 않고, NeptuneError 는 action 이 common.ActionRetry 인 경우만,
+// 그 외 에러는 context 취소/만료가 아니면 재시도한다 (Do 는 ctx 가 종료되면 더 이상 시도하지 않음)
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	var neptuneError *common.NeptuneError
+	if errors.As(err, &neptuneError) {
+		return neptuneError.Retryable()
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Do fn 이 성공하거나, 재시도할 수 없는 에러를 반환하거나, 횟수/시간 제한에 도달할 때까지 반복.
+// 마지막 에러를 반환하며, 대기 중 ctx 가 종료되면 ctx 에러와 마지막 에러를 common.JoinErrors 로 모아 반환
+func Do(ctx context.Context, policy *Policy, fn func(ctx context.Context) error) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = common.DefaultMaxRetryCount
+	}
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = &ConstantBackoff{Delay: time.Second}
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = Retryable
+	}
+
+	startTime := time.Now()
+	var delay time.Duration
+	for number := 1; ; number++ {
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := fn(ctx)
+
+		attempt := &Attempt{Number: number, Err: err, Elapsed: time.Since(startTime)}
+		again := false
+		if err != nil && number < maxAttempts && retryable(err) {
+			delay = backoff.Next(number, delay)
+			if policy.MaxElapsedTime <= 0 || attempt.Elapsed+delay < policy.MaxElapsedTime {
+				attempt.Delay, again = delay, true
+			}
+		}
+		attempt.Final = !again
+		if policy.OnAttempt != nil {
+			policy.OnAttempt(attempt)
+		}
+
+		if err == nil {
+			return nil
+		}
+		if !again {
+			if permanent, ok := err.(*permanentError); ok {
+				return permanent.err
+			}
+			return err
+		}
+
+		timer := time.NewTimer(attempt.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return common.JoinErrors(ctx.Err(), fmt.Errorf("last attempt (%v) : %w", number, err))
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"github.com/hwangtaeseung/neptune-core/pkg/common"
+	"testing"
+	"time"
+)
+
+var errTemporary = errors.New("temporary")
+
+// failing 처음 failures 번은 err 를 반환하는 fn 과 호출 횟수
+func failing(failures int, err error) (func(ctx context.Context) error, *int) {
+	calls := 0
+	return func(ctx context.Context) error {
+		calls++
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func noWait() *Policy {
+	return &Policy{MaxAttempts: 3, Backoff: &ConstantBackoff{}}
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "success after failures", failures: 2, err: errTemporary, wantCalls: 3},
+		{name: "max attempts", failures: 5, err: errTemporary, wantCalls: 3, wantErr: errTemporary},
+		{name: "permanent", failures: 5, err: Permanent(errTemporary), wantCalls: 1, wantErr: errTemporary},
+		{name: "retryable NeptuneError", failures: 1, err: common.ErrSigTerm.Copy(errTemporary), wantCalls: 2},
+		{name: "abort NeptuneError", failures: 5, err: common.ErrExecNonZeroExit.Copy(errTemporary), wantCalls: 1, wantErr: common.ErrExecNonZeroExit},
+		{name: "context error", failures: 5, err: context.DeadlineExceeded, wantCalls: 1, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fn, calls := failing(test.failures, test.err)
+
+			var attempts []*Attempt
+			policy := noWait()
+			policy.OnAttempt = func(attempt *Attempt) {
+				attempts = append(attempts, attempt)
+			}
+			err := Do(context.Background(), policy, fn)
+
+			if test.wantErr == nil && err != nil || test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("Do() error = %v, want %v", err, test.wantErr)
+			}
+			var permanent *permanentError
+			if errors.As(err, &permanent) {
+				t.Errorf("Do() returned Permanent wrapper : %v", err)
+			}
+			if *calls != test.wantCalls || len(attempts) != test.wantCalls {
+				t.Fatalf("calls = %v, attempts = %v, want %v", *calls, len(attempts), test.wantCalls)
+			}
+			for index, attempt := range attempts {
+				if attempt.Number != index+1 || attempt.Final != (index == len(attempts)-1) {
+					t.Errorf("attempt %+v", attempt)
+				}
+			}
+		})
+	}
+}
+
+func TestDoMaxElapsedTime(t *testing.T) {
+
+	fn, calls := failing(100, errTemporary)
+	var last *Attempt
+	policy := &Policy{
+		MaxAttempts:    100,
+		MaxElapsedTime: 250 * time.Millisecond,
+		Backoff:        &ConstantBackoff{Delay: 100 * time.Millisecond},
+		OnAttempt: func(attempt *Attempt) {
+			last = attempt
+		},
+	}
+
+	// 0, 100, 200ms 에 시도하고 다음 시도 (300ms) 는 MaxElapsedTime 을 넘으므로 중단
+	if err := Do(context.Background(), policy, fn); !errors.Is(err, errTemporary) {
+		t.Errorf("Do() error = %v, want %v", err, errTemporary)
+	}
+	if *calls != 3 {
+		t.Errorf("calls = %v, want 3", *calls)
+	}
+	if !last.Final || last.Delay != 0 || last.Elapsed >= policy.MaxElapsedTime {
+		t.Errorf("last attempt = %+v", last)
+	}
+}
+
+func TestDoContext(t *testing.T) {
+
+	// 대기 중 취소되면 ctx 에러와 마지막 에러를 함께 반환
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	fn, calls := failing(100, errTemporary)
+	started := time.Now()
+	err := Do(ctx, &Policy{MaxAttempts: 10, Backoff: &ConstantBackoff{Delay: 10 * time.Second}}, fn)
+
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errTemporary) {
+		t.Errorf("Do() error = %v, want context.Canceled and %v", err, errTemporary)
+	}
+	if *calls != 1 || time.Since(started) > 5*time.Second {
+		t.Errorf("calls = %v, elapsed = %v", *calls, time.Since(started))
+	}
+
+	// 이미 종료된 ctx 면 시도하지 않는다
+	fn, calls = failing(0, nil)
+	if err := Do(ctx, noWait(), fn); !errors.Is(err, context.Canceled) || *calls != 0 {
+		t.Errorf("Do() with canceled ctx = %v (calls:%v)", err, *calls)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "plain error", err: errTemporary, want: true},
+		{name: "permanent", err: Permanent(errTemporary), want: false},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: false},
+		{name: "retry action", err: common.ErrExecTimeout.Copy(errTemporary), want: true},
+		{name: "abort action", err: common.ErrConfigInvalid.Copy(errTemporary), want: false},
+	}
+
+	for _, test := range tests {
+		if got := Retryable(test.err); got != test.want {
+			t.Errorf("Retryable(%v) = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+}
+
+func TestConstantPolicy(t *testing.T) {
+	policy := ConstantPolicy(time.Second)
+	if policy.MaxAttempts != common.DefaultMaxRetryCount {
+		t.Errorf("MaxAttempts = %v, want %v", policy.MaxAttempts, common.DefaultMaxRetryCount)
+	}
+	for attempt := 1; attempt < policy.MaxAttempts; attempt++ {
+		if got := policy.Backoff.Next(attempt, 0); got != time.Second {
+			t.Errorf("Next(%v) = %v, want 1s", attempt, got)
+		}
+	}
+}